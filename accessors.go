@@ -0,0 +1,145 @@
+package csvadapter
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// decodeFunc parses a cell into the field v
+type decodeFunc func(f *field, v reflect.Value, value string) error
+
+// encodeFunc formats the field v into a cell
+//
+// buf is a scratch buffer of the caller the cell can be formatted in,
+// it is grown as needed and must not be retained.
+type encodeFunc func(f *field, v reflect.Value, buf *[]byte) (string, error)
+
+// compile selects the functions decoding and encoding the values of the field of type t
+//
+// basic kinds get typed functions, so the type of the field is only inspected once
+// when the adapter is created; other types go through unmarshalField and marshalField.
+func (f *field) compile(t reflect.Type) {
+	f.nullable = isNullable(t)
+	f.decode, f.encode = compileField(f, t)
+	_, converted := f.fieldConverter(t)
+	f.generated = !converted && !f.json && f.sep == "" && f.base == 10 && !f.hasNumberSeparators() &&
+		len(f.trueValues) == 0 && len(f.falseValues) == 0
+}
+
+// compileField returns the typed functions of the values of type t,
+// or unmarshalField and marshalField if t has no typed functions
+func compileField(f *field, t reflect.Type) (decodeFunc, encodeFunc) {
+	decode := func(f *field, v reflect.Value, value string) error { return unmarshalField(v, value, f) }
+	encode := func(f *field, v reflect.Value, buf *[]byte) (string, error) { return marshalField(v, f) }
+	if _, ok := f.fieldConverter(t); ok || f.json || isSQLNull(t) || isTextType(t) {
+		return decode, encode
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return decodeString, encodeString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decodeInt(t.Bits()), encodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return decodeUint(t.Bits()), encodeUint
+	case reflect.Float32, reflect.Float64:
+		return decodeFloat(t.Bits()), encodeFloat
+	case reflect.Bool:
+		return decodeBool, encodeBool
+	case reflect.Ptr:
+		elemDecode, elemEncode := compileField(f, t.Elem())
+		elemType := t.Elem()
+		return func(f *field, v reflect.Value, value string) error {
+				if v.IsNil() {
+					v.Set(reflect.New(elemType))
+				}
+				return elemDecode(f, v.Elem(), value)
+			}, func(f *field, v reflect.Value, buf *[]byte) (string, error) {
+				if v.IsNil() {
+					return "", nil
+				}
+				return elemEncode(f, v.Elem(), buf)
+			}
+	}
+	return decode, encode
+}
+
+func decodeString(f *field, v reflect.Value, value string) error {
+	v.SetString(value)
+	return nil
+}
+
+func encodeString(f *field, v reflect.Value, buf *[]byte) (string, error) {
+	return v.String(), nil
+}
+
+func decodeInt(bitSize int) decodeFunc {
+	return func(f *field, v reflect.Value, value string) error {
+		if f.hasNumberSeparators() {
+			value = f.normalizeNumber(value)
+		}
+		i, err := strconv.ParseInt(value, f.base, bitSize)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		v.SetInt(i)
+		return nil
+	}
+}
+
+func encodeInt(f *field, v reflect.Value, buf *[]byte) (string, error) {
+	*buf = strconv.AppendInt((*buf)[:0], v.Int(), f.formatBase())
+	return string(*buf), nil
+}
+
+func decodeUint(bitSize int) decodeFunc {
+	return func(f *field, v reflect.Value, value string) error {
+		if f.hasNumberSeparators() {
+			value = f.normalizeNumber(value)
+		}
+		i, err := strconv.ParseUint(value, f.base, bitSize)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		v.SetUint(i)
+		return nil
+	}
+}
+
+func encodeUint(f *field, v reflect.Value, buf *[]byte) (string, error) {
+	*buf = strconv.AppendUint((*buf)[:0], v.Uint(), f.formatBase())
+	return string(*buf), nil
+}
+
+func decodeFloat(bitSize int) decodeFunc {
+	return func(f *field, v reflect.Value, value string) error {
+		if f.hasNumberSeparators() {
+			value = f.normalizeNumber(value)
+		}
+		x, err := strconv.ParseFloat(value, bitSize)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		v.SetFloat(x)
+		return nil
+	}
+}
+
+func encodeFloat(f *field, v reflect.Value, buf *[]byte) (string, error) {
+	*buf = strconv.AppendFloat((*buf)[:0], v.Float(), 'f', 6, 64)
+	return f.localizeNumber(string(*buf)), nil
+}
+
+func decodeBool(f *field, v reflect.Value, value string) error {
+	b, err := f.parseBool(value)
+	if err != nil {
+		return errors.Join(ErrParsingType, err)
+	}
+	v.SetBool(b)
+	return nil
+}
+
+func encodeBool(f *field, v reflect.Value, buf *[]byte) (string, error) {
+	return f.formatBool(v.Bool()), nil
+}
@@ -0,0 +1,49 @@
+package csvadapter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompiledFields(t *testing.T) {
+	type Level string
+	type Row struct {
+		Name   string   `csva:"name"`
+		Level  Level    `csva:"level"`
+		Small  int8     `csva:"small"`
+		Big    *uint64  `csva:"big"`
+		Ratio  float32  `csva:"ratio,decimal=comma"`
+		Amount **int    `csva:"amount,thousands=dot"`
+		Flag   *bool    `csva:"flag,omitempty"`
+		Tags   []string `csva:"tags,sep=|"`
+	}
+	adapter, err := NewCSVAdapter[Row]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	input := "name,level,small,big,ratio,amount,flag,tags\nJohn Doe,high,-8,18446744073709551615,\"0,5\",1.234,,a|b\n"
+	rows, err := adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	row := rows[0]
+	if row.Name != name || row.Level != "high" || row.Small != -8 || *row.Big != 1<<64-1 ||
+		row.Ratio != 0.5 || **row.Amount != 1234 || row.Flag != nil || len(row.Tags) != 2 {
+		t.Errorf("unexpected row %+v", row)
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader(strings.Replace(input, "-8", "-200", 1))); ErrorCode(err) != CodeParseInt {
+		t.Errorf("expected %s, got %v", CodeParseInt, err)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, rows); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	expected := "name,level,small,big,ratio,amount,flag,tags\nJohn Doe,high,-8,18446744073709551615,\"0,500000\",1234,,a|b\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
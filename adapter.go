@@ -1,27 +1,82 @@
 package csvadapter
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"encoding"
-	"encoding/csv"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type field struct {
 	name      string // name of the field in the struct
 	alias     string // name of the field in the csv
+	aliased   bool   // if the alias is set by the tag or the AliasOverrides option
+	index     []int  // index path of the field in the struct
 	omitEmpty bool   // if the field can be empty
+	required  bool   // if the column must exist and the cell must not be empty when reading
+	unique    bool   // if the values of the column must be unique when reading
+	meta      string // kind of provenance information for metadata fields
+	sep       string // separator of the elements of slice fields
+	enc       string // encoding of []byte fields, base64 or hex
+	column    int    // position of the column for positional fields, -1 if not set
+
+	defaultValue string // value used when the cell is empty or the column is missing
+	hasDefault   bool   // if defaultValue is set
+
+	converters map[reflect.Type]converter // custom converters of the adapter, shared by all fields
+	converter  *converter                 // named converter selected with the conv tag option
+
+	min     *limit         // minimum value (or length) when reading, nil if not set
+	max     *limit         // maximum value (or length) when reading, nil if not set
+	pattern *regexp.Regexp // pattern the cells must match when reading, nil if not set
+
+	json      bool // if the cells contain the field encoded as JSON
+	base      int  // base of integer fields, 0 to detect the prefix when reading
+	thousands rune // thousands separator of numbers, 0 if none
+	decimal   rune // decimal separator of numbers, 0 for "."
+
+	trueValues  []string // representations of true, the first one is written
+	falseValues []string // representations of false, the first one is written
+
+	layouts  []string       // layouts of time fields tried in order, the first one is written; nil for RFC 3339
+	location *time.Location // location of the timestamps without zone of time fields, nil for UTC
+	utc      bool           // if time fields are written in UTC
+
+	null    string   // representation of nil values set by the null tag option, "" to disable
+	nullTag bool     // if the null tag option is set, overriding the NullString option
+	empties []string // cells read as empty, the first one is written for empty optional fields
+	trim    bool     // if leading and trailing whitespace is trimmed from the cells when reading
+
+	nullable bool       // if the field can hold nil values
+	decode   decodeFunc // parses the cells of the field
+	encode   encodeFunc // formats the field into cells
+
+	generated bool // if FieldUnmarshaler and FieldMarshaler may handle the field, i.e. it has the default formatting
 }
 
 // CSVAdapter is a struct that adapts a struct to a csv file
 type CSVAdapter[T any] struct {
 	structType reflect.Type
 	fields     []field // fields of the struct
+	metaFields []field // fields filled with provenance information
+	restField  *field  // field collecting the columns not mapped to other fields
+	positional bool    // if fields are mapped to column positions instead of header names
+	fallback   bool    // if fields are mapped to header names, falling back to their column positions
+	pointer    bool    // if T is a pointer to the struct
+
+	converters map[reflect.Type]converter // registered and adapter converters
 
 	options *csvAdapterOptions
 }
@@ -32,16 +87,30 @@ func (c CSVAdapter[T]) String() string {
 
 // NewCSVAdapter creates a new CSVAdapter
 func NewCSVAdapter[T any](options ...csvAdapterOption) (*CSVAdapter[T], error) {
-	var TEmpty T
-	t := reflect.TypeOf(TEmpty)
+	return newCSVAdapter[T](reflect.TypeFor[T](), nil, options)
+}
+
+// newCSVAdapter creates a new CSVAdapter for the struct type t (or pointer to it)
+//
+// T is either t or any, e.g. for struct types built at runtime.
+//
+// names are the names and aliases of the top-level fields by index, for fields
+// whose names cannot be written in a tag; nil keeps the names of the struct.
+func newCSVAdapter[T any](t reflect.Type, names []string, options []csvAdapterOption) (*CSVAdapter[T], error) {
 
-	// TODO: Support for pointers/maps
+	// pointers to structs are yielded and accepted as is
+	pointer := t.Kind() == reflect.Ptr
+	if pointer {
+		t = t.Elem()
+	}
+	// TODO: Support for maps
 	if t.Kind() != reflect.Struct {
 		return nil, errors.Join(ErrorNotStruct, fmt.Errorf("type %s", t.Kind()))
 	}
 
 	csvAdapter := &CSVAdapter[T]{
 		structType: t,
+		pointer:    pointer,
 		fields:     make([]field, 0),
 		options:    newCSVAdapterOptions(),
 	}
@@ -49,18 +118,416 @@ func NewCSVAdapter[T any](options ...csvAdapterOption) (*CSVAdapter[T], error) {
 	for _, option := range options {
 		option(csvAdapter.options)
 	}
+	// the options must not change with the maps and slices passed to them
+	csvAdapter.options = csvAdapter.options.clone()
+	if err := csvAdapter.checkRowFuncs(); err != nil {
+		return nil, err
+	}
+	if err := csvAdapter.options.checkBOM(); err != nil {
+		return nil, err
+	}
+	csvAdapter.converters = resolveConverters(csvAdapter.options.converters)
+	if err := csvAdapter.parseFields(t, nil, "", "", false); err != nil {
+		return nil, err
+	}
+	if names != nil {
+		// the options referring to aliases are checked below. The fields of
+		// flattened columns keep the path below the struct field of the column
+		for i := range csvAdapter.fields {
+			f := &csvAdapter.fields[i]
+			prefix, name := t.Field(f.index[0]).Name, names[f.index[0]]
+			if rest, ok := strings.CutPrefix(f.name, prefix); ok {
+				f.name = name + rest
+			}
+			if rest, ok := strings.CutPrefix(f.alias, prefix); ok {
+				f.alias = name + rest
+			}
+		}
+	}
+	if err := csvAdapter.overrideAliases(); err != nil {
+		return nil, err
+	}
+	if err := csvAdapter.checkPositional(); err != nil {
+		return nil, err
+	}
+	if err := csvAdapter.orderColumns(); err != nil {
+		return nil, err
+	}
+	if err := csvAdapter.checkDedupe(); err != nil {
+		return nil, err
+	}
+
+	return csvAdapter, nil
+}
+
+// checkRowFuncs checks that the row functions of the options take rows of type T
+func (c *CSVAdapter[T]) checkRowFuncs() error {
+	rowType := reflect.TypeFor[T]()
+	check := func(option string, typ reflect.Type) error {
+		if typ != rowType {
+			return errors.Join(ErrInvalidConfig, fmt.Errorf("%s of %s used with rows of %s", option, typ, rowType))
+		}
+		return nil
+	}
+	var errs []error
+	if c.options.filter != nil {
+		errs = append(errs, check("Filter", c.options.filter.typ))
+	}
+	if c.options.mapRead != nil {
+		errs = append(errs, check("MapRead", c.options.mapRead.typ))
+	}
+	if c.options.mapWrite != nil {
+		errs = append(errs, check("MapWrite", c.options.mapWrite.typ))
+	}
+	return errors.Join(errs...)
+}
+
+// checkPositional enables the positional mode if all fields are mapped to column positions,
+// or the fallback mode if they all have an alias too
+func (c *CSVAdapter[T]) checkPositional() error {
+	positions := make(map[int]string, len(c.fields))
+	for _, f := range c.fields {
+		if f.column < 0 {
+			continue
+		}
+		if other, isFound := positions[f.column]; isFound {
+			return errors.Join(ErrInvalidTag, fmt.Errorf("fields %s and %s: same %s %d", other, f.name, _TAG_INDEX, f.column))
+		}
+		positions[f.column] = f.name
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	if len(positions) != len(c.fields) {
+		return errors.Join(ErrInvalidTag, fmt.Errorf("either all or none of the fields must have an %s", _TAG_INDEX))
+	}
+	if c.restField != nil {
+		return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s is not supported with %s", c.restField.name, _TAG_REST, _TAG_INDEX))
+	}
+	if slices.ContainsFunc(c.fields, func(f field) bool { return !f.aliased }) {
+		c.positional = true
+		return nil
+	}
+	// the header is written in the order of the positions
+	c.fallback = true
+	slices.SortStableFunc(c.fields, func(a, b field) int { return a.column - b.column })
+	return nil
+}
+
+// overrideAliases replaces the aliases of the fields named by the AliasOverrides option
+func (c *CSVAdapter[T]) overrideAliases() error {
+	for name, alias := range c.options.aliasOverrides {
+		i := slices.IndexFunc(c.fields, func(f field) bool { return f.name == name })
+		if i < 0 {
+			return errors.Join(ErrInvalidConfig, ErrFieldNotFound, fmt.Errorf("field %s", name))
+		}
+		if alias == "" {
+			return errors.Join(ErrInvalidConfig, ErrAliasNotFound, fmt.Errorf("field %s", name))
+		}
+		c.fields[i].alias = alias
+		c.fields[i].aliased = true
+	}
+	return nil
+}
 
+// orderColumns moves the fields listed by the ColumnOrder option first, in that order
+func (c *CSVAdapter[T]) orderColumns() error {
+	if len(c.options.columnOrder) == 0 {
+		return nil
+	}
+	ordered := make([]field, 0, len(c.fields))
+	for _, alias := range c.options.columnOrder {
+		i := slices.IndexFunc(c.fields, func(f field) bool { return f.alias == alias })
+		if i < 0 {
+			if slices.ContainsFunc(ordered, func(f field) bool { return f.alias == alias }) {
+				return errors.Join(ErrInvalidConfig, fmt.Errorf("column %s is ordered twice", alias))
+			}
+			return errors.Join(ErrInvalidConfig, ErrUnknownColumn, fmt.Errorf("column %s", alias))
+		}
+		ordered = append(ordered, c.fields[i])
+		c.fields = slices.Delete(c.fields, i, i+1)
+	}
+	c.fields = append(ordered, c.fields...)
+	return nil
+}
+
+// Select returns a copy of the adapter reading and writing only the given columns
+//
+// the other fields are left empty when reading. Unknown columns are rejected with ErrUnknownColumn.
+func (c *CSVAdapter[T]) Select(aliases ...string) (*CSVAdapter[T], error) {
+	if err := c.checkAliases(aliases); err != nil {
+		return nil, err
+	}
+	return c.project(func(f field) bool { return slices.Contains(aliases, f.alias) }), nil
+}
+
+// Omit returns a copy of the adapter ignoring the given columns
+//
+// the omitted fields are left empty when reading. Unknown columns are rejected with ErrUnknownColumn.
+func (c *CSVAdapter[T]) Omit(aliases ...string) (*CSVAdapter[T], error) {
+	if err := c.checkAliases(aliases); err != nil {
+		return nil, err
+	}
+	return c.project(func(f field) bool { return !slices.Contains(aliases, f.alias) }), nil
+}
+
+// checkAliases checks that all aliases are columns of the adapter
+func (c *CSVAdapter[T]) checkAliases(aliases []string) error {
+	for _, alias := range aliases {
+		if !slices.ContainsFunc(c.fields, func(f field) bool { return f.alias == alias }) {
+			return errors.Join(ErrUnknownColumn, fmt.Errorf("column %s", alias))
+		}
+	}
+	return nil
+}
+
+// with returns a copy of the adapter with options applied on top of its options
+//
+// the options describing the fields are fixed when the adapter is created,
+// changing them is rejected with ErrInvalidConfig.
+func (c *CSVAdapter[T]) with(options []csvAdapterOption) (*CSVAdapter[T], error) {
+	if len(options) == 0 {
+		return c, nil
+	}
+	changed := newCSVAdapterOptions()
+	for _, option := range options {
+		option(changed)
+	}
+	var fixed []string
+	for name, value := range changed.fieldOptions() {
+		if !reflect.ValueOf(value).IsZero() {
+			fixed = append(fixed, name)
+		}
+	}
+	if len(fixed) > 0 {
+		slices.Sort(fixed)
+		return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("%s can only be set when creating the adapter", strings.Join(fixed, ", ")))
+	}
+
+	o := c.options.clone()
+	for _, option := range options {
+		option(o)
+	}
+	adapter := *c
+	adapter.options = o
+	if err := adapter.checkRowFuncs(); err != nil {
+		return nil, err
+	}
+	if err := adapter.options.checkBOM(); err != nil {
+		return nil, err
+	}
+	if err := adapter.checkDedupe(); err != nil {
+		return nil, err
+	}
+	return &adapter, nil
+}
+
+// project returns a copy of the adapter with the fields for which keep returns true
+func (c *CSVAdapter[T]) project(keep func(f field) bool) *CSVAdapter[T] {
+	projected := *c
+	projected.fields = make([]field, 0, len(c.fields))
+	for _, f := range c.fields {
+		if keep(f) {
+			projected.fields = append(projected.fields, f)
+		}
+	}
+	return &projected
+}
+
+// resolveColumns returns the csv column of each field (-1 if missing)
+// and the columns not mapped to any field
+//
+// the header is nil for positional adapters, with the NoHeader option and in
+// fallback mode when the first record is not a header. In fallback mode, the
+// fields whose alias is not in the header are mapped to their column position.
+func (c *CSVAdapter[T]) resolveColumns(header []string) ([]int, []int, error) {
+	columns := make([]int, len(c.fields))
+	if c.positional {
+		for i, f := range c.fields {
+			columns[i] = f.column
+		}
+		return columns, nil, nil
+	}
+	if header == nil {
+		// no header, fields are mapped by their order
+		for i, f := range c.fields {
+			columns[i] = i
+			if c.fallback {
+				columns[i] = f.column
+			}
+		}
+		return columns, nil, nil
+	}
+
+	// create a map of the columns order
+	columnsOrder := make(map[string][]int, len(header))
+	for i, h := range header {
+		key := c.options.headerKey(h)
+		columnsOrder[key] = append(columnsOrder[key], i)
+		if len(columnsOrder[key]) > 1 && c.options.duplicateHeaders == DuplicateError {
+			return nil, nil, errors.Join(ErrDuplicateColumn, fmt.Errorf("column %s", h))
+		}
+	}
+
+	// check if all fields are present in the csv
+	mapped := make([]bool, len(header))
+	used := make(map[string]int) // number of columns bound per key, for DuplicateSequential
+	for i, f := range c.fields {
+		key := c.options.headerKey(f.alias)
+		indices := columnsOrder[key]
+		index, isFound := -1, len(indices) > 0
+		switch c.options.duplicateHeaders {
+		case DuplicateFirstWins:
+			if isFound {
+				index = indices[0]
+			}
+		case DuplicateSequential:
+			isFound = used[key] < len(indices)
+			if isFound {
+				index = indices[used[key]]
+				used[key]++
+			}
+		default:
+			if isFound {
+				index = indices[len(indices)-1]
+			}
+		}
+		if !isFound && c.fallback && f.column < len(header) {
+			index, isFound = f.column, true
+		}
+		if !isFound {
+			if c.canOmitColumn(&f) {
+				columns[i] = -1
+				continue
+			}
+			return nil, nil, errors.Join(ErrFieldNotFound, fmt.Errorf("field %s", f.alias))
+		}
+		columns[i] = index
+		mapped[index] = true
+	}
+
+	// columns not mapped to any field, the row hash column written with the adapter is known
+	var restColumns []int
+	for i := range header {
+		if !mapped[i] && !c.isRowHashColumn(header[i]) {
+			restColumns = append(restColumns, i)
+		}
+	}
+	if c.options.strictHeader && c.restField == nil && len(restColumns) > 0 {
+		unknown := make([]string, len(restColumns))
+		for i, column := range restColumns {
+			unknown[i] = header[column]
+		}
+		return nil, nil, errors.Join(ErrUnknownColumn, fmt.Errorf("columns %s", strings.Join(unknown, ", ")))
+	}
+	return columns, restColumns, nil
+}
+
+// isHeader reports whether a record contains the alias of any field,
+// i.e. whether it is a header in fallback mode
+func (c *CSVAdapter[T]) isHeader(record []string) bool {
+	for _, f := range c.fields {
+		key := c.options.headerKey(f.alias)
+		if slices.ContainsFunc(record, func(column string) bool { return c.options.headerKey(column) == key }) {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyValue returns the cell written for the empty values of optional fields
+func (f *field) emptyValue() string {
+	if len(f.empties) == 0 {
+		return ""
+	}
+	return f.empties[0]
+}
+
+// null returns the representation of nil values of a field, "" if there is none
+//
+// it is resolved from the options of each read or write, so a dialect passed
+// per call (e.g. PostgresDialect) sets it for the fields without null tag option.
+func (c *CSVAdapter[T]) null(f *field) string {
+	if f.nullTag {
+		return f.null
+	}
+	return c.options.nullString
+}
+
+// nullValue returns the cell written for the nil values of a field,
+// the empty value if there is no null representation
+func (c *CSVAdapter[T]) nullValue(f *field) string {
+	if null := c.null(f); null != "" {
+		return null
+	}
+	return f.emptyValue()
+}
+
+// normalizeCell returns the value of a cell read for a field, with the
+// trim tag option applied and the null and empty representations as ""
+func (c *CSVAdapter[T]) normalizeCell(f *field, value string) string {
+	if f.trim {
+		value = strings.TrimSpace(value)
+	}
+	if null := c.null(f); (null != "" && value == null) || slices.Contains(f.empties, value) {
+		return ""
+	}
+	return value
+}
+
+// canOmitColumn reports whether the column of a field can be missing from the header
+func (c *CSVAdapter[T]) canOmitColumn(f *field) bool {
+	return !f.required && (f.omitEmpty || f.hasDefault || c.options.allowMissingColumns)
+}
+
+// parseFields parses the fields of the struct type t and appends them to the adapter
+//
+// index, namePrefix and aliasPrefix describe the path of t for flattened nested structs.
+func (c *CSVAdapter[T]) parseFields(t reflect.Type, index []int, namePrefix, aliasPrefix string, omitEmpty bool) error {
 iterOverFields:
 	for i := 0; i < t.NumField(); i++ {
 		field := field{}
 		fld := t.Field(i)
 		tag := fld.Tag.Get(_TAG)
-		field.name = fld.Name
-		if !csvAdapter.options.noImplicitAlias {
-			field.alias = fld.Name // default alias
+		field.name = namePrefix + fld.Name
+		field.index = append(slices.Clone(index), i)
+		field.omitEmpty = omitEmpty
+		field.column = -1
+		field.base = 10
+		field.thousands = c.options.thousands
+		field.decimal = c.options.decimal
+		field.converters = c.converters
+		field.trueValues = c.options.trueValues
+		field.falseValues = c.options.falseValues
+		field.trim = c.options.trimSpace
+		field.location = c.options.location
+		field.utc = c.options.writeUTC
+		if !c.options.noImplicitAlias {
+			field.alias = c.options.implicitAliasStyle.alias(fld.Name) // default alias
 		}
 		isAliasSet := false
+		flatten := false
+		rest := false
+		// patterns may contain "," and "=", so the pattern option takes the rest of the tag
+		if i := strings.Index(","+tag, ","+_TAG_PATTERN+"="); i >= 0 {
+			pattern, err := regexp.Compile(tag[i+len(_TAG_PATTERN)+1:])
+			if err != nil {
+				return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s", field.name, _TAG_PATTERN), err)
+			}
+			field.pattern = pattern
+			tag = tag[:max(i-1, 0)]
+		}
 		tagParts := strings.Split(tag, ",")
+		if tagParts[0] == _TAG_SKIP {
+			if len(tagParts) == 1 {
+				continue iterOverFields
+			}
+			if err := parseMetaField(&field, fld, tagParts[1:]); err != nil {
+				return err
+			}
+			c.metaFields = append(c.metaFields, field)
+			continue iterOverFields
+		}
 		for _, part := range tagParts {
 			if part == "" {
 				continue
@@ -75,214 +542,510 @@ iterOverFields:
 			} else if len(kv) == 2 {
 				key, value = kv[0], kv[1]
 			} else {
-				return nil, errors.Join(ErrInvalidTag, fmt.Errorf("tag %s", part))
+				return errors.Join(ErrInvalidTag, fmt.Errorf("tag %s", part))
 			}
 			switch key {
 			case _TAG_ALIAS:
 				field.alias = value
+				field.aliased = true
 			case _TAG_OMITEMPTY:
 				field.omitEmpty = true
+			case _TAG_REQUIRED:
+				field.required = true
+			case _TAG_UNIQUE:
+				field.unique = true
+			case _TAG_FLATTEN:
+				flatten = true
+			case _TAG_REST:
+				if fld.Type != reflect.TypeOf(map[string]string(nil)) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a map[string]string", field.name, _TAG_REST))
+				}
+				if c.restField != nil {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: only one %s field is allowed", field.name, _TAG_REST))
+				}
+				rest = true
+			case _TAG_DEFAULT:
+				field.defaultValue = value
+				field.hasDefault = true
+			case _TAG_INDEX:
+				column, err := strconv.Atoi(value)
+				if err != nil || column < 0 {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s %q", field.name, _TAG_INDEX, value))
+				}
+				field.column = column
+			case _TAG_MIN, _TAG_MAX:
+				l, err := parseLimit(fld.Type, value)
+				if err != nil {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s", field.name, key), err)
+				}
+				if key == _TAG_MIN {
+					field.min = l
+				} else {
+					field.max = l
+				}
+			case _TAG_JSON:
+				field.json = true
+			case _TAG_BASE:
+				base, err := strconv.Atoi(value)
+				if err != nil || base == 1 || base < 0 || base > 36 || !isIntegerField(fld.Type) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires an integer and a base between 2 and 36 (or 0)", field.name, _TAG_BASE))
+				}
+				field.base = base
+			case _TAG_THOUSANDS, _TAG_DECIMAL:
+				sep, err := parseSeparator(value)
+				if err != nil || (key == _TAG_DECIMAL && sep == 0) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s", field.name, key), err)
+				}
+				if key == _TAG_THOUSANDS {
+					field.thousands = sep
+				} else {
+					field.decimal = sep
+				}
+			case _TAG_TRUE, _TAG_FALSE:
+				values := strings.Split(value, "|")
+				if value == "" || !isBoolField(fld.Type) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a bool and values", field.name, key))
+				}
+				if key == _TAG_TRUE {
+					field.trueValues = values
+				} else {
+					field.falseValues = values
+				}
+			case _TAG_NULL:
+				field.null, field.nullTag = value, true
+			case _TAG_TRIM:
+				field.trim = true
+			case _TAG_CONV:
+				conv, ok := c.options.namedConverters[value]
+				if !ok {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: unknown converter %q", field.name, value))
+				}
+				if !conv.convertsField(fld.Type) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: converter %q converts %s", field.name, value, conv.typ))
+				}
+				field.converter = &conv
+			case _TAG_SEP:
+				if value == "" || indirectType(fld.Type).Kind() != reflect.Slice {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a slice and a separator", field.name, _TAG_SEP))
+				}
+				field.sep = value
+			case _TAG_ENC:
+				if value != _ENC_BASE64 && value != _ENC_HEX {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: unknown %s %q", field.name, _TAG_ENC, value))
+				}
+				if !isBytesField(fld.Type) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a []byte", field.name, _TAG_ENC))
+				}
+				field.enc = value
+			case _TAG_EMPTY:
+				if value == "" {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a value", field.name, _TAG_EMPTY))
+				}
+				field.empties = strings.Split(value, "|")
+			case _TAG_FORMAT, _TAG_TZ:
+				if !isTimeField(fld.Type) {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a time.Time", field.name, key))
+				}
+				if key == _TAG_FORMAT {
+					field.layouts = strings.Split(value, "|")
+					for i, layout := range field.layouts {
+						if named, ok := namedLayouts[layout]; ok {
+							field.layouts[i] = named
+						}
+					}
+					continue
+				}
+				location, err := time.LoadLocation(value)
+				if err != nil {
+					return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s", field.name, _TAG_TZ), err)
+				}
+				field.location = location
 			default:
 				// first part without key is the alias
 				if !isAliasSet {
 					field.alias = key
+					field.aliased = true
 					isAliasSet = true
 				} else {
-					return nil, errors.Join(ErrUnsupportedTag, fmt.Errorf("tag %s", part))
+					return errors.Join(ErrUnsupportedTag, fmt.Errorf("tag %s", part))
 				}
 			}
 		}
 
-		if field.alias == "" {
-			return nil, errors.Join(ErrAliasNotFound, fmt.Errorf("field %s", field.name))
+		if flatten {
+			nested := indirectType(fld.Type)
+			if nested.Kind() != reflect.Struct {
+				return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s requires a struct", field.name, _TAG_FLATTEN))
+			}
+			prefix := aliasPrefix
+			if field.alias != "" {
+				prefix += field.alias + _FLATTEN_SEPARATOR
+			}
+			if err := c.parseFields(nested, field.index, field.name+".", prefix, field.omitEmpty); err != nil {
+				return err
+			}
+			continue iterOverFields
+		}
+
+		if rest {
+			c.restField = &field
+			continue iterOverFields
+		}
+
+		if field.hasDefault && field.required {
+			return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: %s and %s are exclusive", field.name, _TAG_DEFAULT, _TAG_REQUIRED))
+		}
+		field.compile(fld.Type)
+		if field.hasDefault {
+			// check the default value can be parsed into the field
+			if err := field.decode(&field, reflect.New(fld.Type).Elem(), field.defaultValue); err != nil {
+				return errors.Join(ErrInvalidTag, fmt.Errorf("field %s: invalid %s", field.name, _TAG_DEFAULT), err)
+			}
+		}
+
+		// empty cells are the null of database/sql nullable types
+		if isSQLNull(fld.Type) {
+			field.omitEmpty = true
+		}
+
+		// an explicit empty alias maps a column with an empty name
+		if field.alias == "" && !field.aliased {
+			return errors.Join(ErrAliasNotFound, fmt.Errorf("field %s", field.name))
 		}
+		field.alias = aliasPrefix + field.alias
 
-		csvAdapter.fields = append(csvAdapter.fields, field)
+		c.fields = append(c.fields, field)
 	}
 
-	return csvAdapter, nil
+	return nil
+}
+
+// parseMetaField parses the options of a `csva:"-,<meta>"` tag
+func parseMetaField(f *field, fld reflect.StructField, parts []string) error {
+	if len(parts) != 1 {
+		return errors.Join(ErrUnsupportedTag, fmt.Errorf("tag %s", strings.Join(parts, ",")))
+	}
+	kind := fld.Type.Kind()
+	switch parts[0] {
+	case _TAG_META_SOURCE_FILE, _TAG_META_ROW_HASH:
+		if kind != reflect.String {
+			return errors.Join(ErrUnprocessableType, fmt.Errorf("field %s: %s requires a string", fld.Name, parts[0]))
+		}
+	case _TAG_META_BYTE_OFFSET:
+		switch kind {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return errors.Join(ErrUnprocessableType, fmt.Errorf("field %s: %s requires an integer", fld.Name, parts[0]))
+		}
+	default:
+		return errors.Join(ErrUnsupportedTag, fmt.Errorf("tag %s", parts[0]))
+	}
+	f.meta = parts[0]
+	return nil
 }
 
 // FromCSV reads a csv file and fills a slice of structs
-func (c *CSVAdapter[T]) FromCSV(reader io.Reader) (iter.Seq2[T, error], error) {
-	csvReader := csv.NewReader(reader)
-	c.options.applyReader(csvReader)
+//
+// options apply to this file only, on top of the options of the adapter.
+func (c *CSVAdapter[T]) FromCSV(reader io.Reader, options ...csvAdapterOption) (iter.Seq2[T, error], error) {
+	decoder, err := c.NewDecoder(reader, options...)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.All(), nil
+}
 
-	header, err := csvReader.Read()
+// ToCSV writes a slice of structs to a csv file
+//
+// options apply to this file only, on top of the options of the adapter.
+func (c *CSVAdapter[T]) ToCSV(writer io.Writer, data iter.Seq[T], options ...csvAdapterOption) error {
+	encoder, err := c.NewEncoder(writer, options...)
 	if err != nil {
-		return nil, errors.Join(ErrReadingCSVLines, err)
+		return err
 	}
-	// create a map of the columns order
-	columnsOrder := make(map[string]int, len(header))
-	for i, h := range header {
-		columnsOrder[h] = i
+	return encodeAll(encoder, data)
+}
+
+// UnmarshalAll reads all the rows of a csv file
+//
+// it stops at the first error.
+func (c *CSVAdapter[T]) UnmarshalAll(reader io.Reader) ([]T, error) {
+	rows, err := c.FromCSV(reader)
+	if err != nil {
+		return nil, err
 	}
+	items := make([]T, 0)
+	for item, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
 
-	// check if all fields are present in the csv
-	for _, f := range c.fields {
-		if _, isFound := columnsOrder[f.alias]; !isFound {
-			if f.omitEmpty {
-				continue
-			}
-			return nil, errors.Join(ErrFieldNotFound, fmt.Errorf("field %s", f.alias))
+// UnmarshalAllLenient reads all the valid rows of a csv file
+//
+// invalid rows are skipped and their errors are returned as a *RowErrors
+// together with the valid rows. Errors of the header are returned as is.
+func (c *CSVAdapter[T]) UnmarshalAllLenient(reader io.Reader) ([]T, error) {
+	rows, err := c.FromCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0)
+	var rowErrs []error
+	for item, err := range rows {
+		if err != nil {
+			rowErrs = append(rowErrs, err)
+			continue
 		}
+		items = append(items, item)
+	}
+	if len(rowErrs) > 0 {
+		return items, &RowErrors{Errors: rowErrs}
 	}
+	return items, nil
+}
 
-	return func(yield func(T, error) bool) {
-		var TEmpty T
-		line := 0
-	loopOverLines:
-		for {
-			line++
-			record, err := csvReader.Read()
-			if err == io.EOF {
-				break loopOverLines
-			}
-			if err != nil {
-				if !yield(TEmpty, errors.Join(ErrReadingCSVLines, err)) {
-					return
-				}
-				continue loopOverLines
-			}
-			s := reflect.New(c.structType).Elem()
-			for _, f := range c.fields {
-				fieldErr := errors.Join(
-					ErrProcessingCSVLines,
-					ReadingError{
-						Line:       line,
-						Field:      f.name,
-						FieldAlias: f.alias,
-					})
-				index, isFound := columnsOrder[f.alias]
-				if !isFound && f.omitEmpty {
-					continue
-				} else if !isFound { // I think its actually impossible to reach this point
-					if !yield(TEmpty, errors.Join(fieldErr, ErrFieldNotFound)) {
-						return
-					}
-					continue loopOverLines
-				}
-				value := record[index]
-				if value == "" && f.omitEmpty {
-					continue
-				} else if value == "" {
-					if !yield(TEmpty, errors.Join(fieldErr, ErrEmptyValue)) {
-						return
-					}
-					continue loopOverLines
-				}
-				field := s.FieldByName(f.name)
-				if err := unmarshalField(field, value); err != nil {
-					if !yield(TEmpty, errors.Join(fieldErr, err)) {
-						return
-					}
-					continue loopOverLines
-				}
-			}
-			if !yield(s.Interface().(T), nil) {
-				return
+// MarshalAll writes a slice of structs to a csv file
+func (c *CSVAdapter[T]) MarshalAll(writer io.Writer, items []T) error {
+	return c.ToCSV(writer, slices.Values(items))
+}
+
+// formatBase returns the base integers are written in, decimal if the base is detected when reading
+func (f *field) formatBase() int {
+	if f.base == 0 {
+		return 10
+	}
+	return f.base
+}
+
+// parseBool parses a boolean, accepting the true and false values of the field besides Go's literals
+func (f *field) parseBool(value string) (bool, error) {
+	if slices.ContainsFunc(f.trueValues, func(v string) bool { return strings.EqualFold(v, value) }) {
+		return true, nil
+	}
+	if slices.ContainsFunc(f.falseValues, func(v string) bool { return strings.EqualFold(v, value) }) {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// formatBool formats a boolean with the first true or false value of the field, if any
+func (f *field) formatBool(b bool) string {
+	if b && len(f.trueValues) > 0 {
+		return f.trueValues[0]
+	}
+	if !b && len(f.falseValues) > 0 {
+		return f.falseValues[0]
+	}
+	return strconv.FormatBool(b)
+}
+
+// isIntegerField reports whether t is an integer, or a pointer or slice of integers
+func isIntegerField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// isBytesField reports whether t is a []byte, or a slice of []byte (for the sep tag option)
+func isBytesField(t reflect.Type) bool {
+	t = indirectType(t)
+	return isBytes(t) || (t.Kind() == reflect.Slice && isBytes(indirectType(t.Elem())))
+}
+
+// isBytes reports whether t is a slice of bytes
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// isBoolField reports whether t is a bool, or a pointer or slice of bools
+func isBoolField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+// formatDriverValue formats a value returned by driver.Valuer
+func formatDriverValue(value driver.Value) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', 6, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	default:
+		return "", errors.Join(ErrUnprocessableType, fmt.Errorf("driver value %T", value))
+	}
+}
+
+// indirectType returns the type pointed to by t, or t itself if it's not a pointer
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldByIndex returns the nested field of v by its index path,
+// allocating nil pointers to flattened structs on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
 			}
+			v = v.Elem()
 		}
-	}, nil
+		v = v.Field(x)
+	}
+	return v
 }
 
-// ToCSV writes a slice of structs to a csv file
-func (c *CSVAdapter[T]) ToCSV(writer io.Writer, data iter.Seq[T]) error {
-	csvWriter := csv.NewWriter(writer)
-	c.options.applyWriter(csvWriter)
-	defer csvWriter.Flush()
-
-	// write header
-	if c.options.writeHeader {
-		header := make([]string, len(c.fields))
-		for i, f := range c.fields {
-			header[i] = f.alias
+// fieldConverter returns the converter of values of type t,
+// preferring the named converter of the field
+func (f *field) fieldConverter(t reflect.Type) (converter, bool) {
+	if f.converter != nil && f.converter.typ == t {
+		return *f.converter, true
+	}
+	conv, ok := f.converters[t]
+	return conv, ok
+}
+
+// unmarshals a string value to a field
+// based on the type of the field
+func unmarshalField(field reflect.Value, value string, f *field) error {
+	// custom converters
+	if conv, ok := f.fieldConverter(field.Type()); ok {
+		v, err := conv.parse(value)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
 		}
-		if err := csvWriter.Write(header); err != nil {
-			return errors.Join(ErrReadingCSV, err)
+		field.Set(v)
+		return nil
+	}
+
+	// bytes encoded as text
+	if f.enc != "" && isBytes(field.Type()) {
+		b, err := decodeBytes(value, f.enc)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
 		}
+		field.SetBytes(b)
+		return nil
 	}
 
-	// write records
-	line := 0
-	for item := range data {
-		line++
-		itemV := reflect.ValueOf(item)
-		record := make([]string, len(c.fields))
-		for i, f := range c.fields {
-			fieldErr := errors.Join(
-				ErrProcessingCSVLines,
-				ReadingError{
-					Line:       line,
-					Field:      f.name,
-					FieldAlias: f.alias,
-				})
-			field := itemV.FieldByName(f.name)
-			if !field.IsValid() {
-				return errors.Join(fieldErr, ErrFieldNotFound)
-			}
-			if field.Kind() == reflect.Ptr && field.IsNil() {
-				continue
+	// slices with a separator
+	if field.Kind() == reflect.Slice && f.sep != "" {
+		parts := strings.Split(value, f.sep)
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := unmarshalField(slice.Index(i), part, f); err != nil {
+				return err
 			}
-			str, err := marshalField(field)
-			if err != nil {
-				return errors.Join(fieldErr, err)
-			}
-			if str == "" && f.omitEmpty {
-				continue
-			} else if str == "" {
-				return errors.Join(fieldErr, ErrEmptyValue)
-			}
-			record[i] = str
 		}
-		if err := csvWriter.Write(record); err != nil {
-			return errors.Join(ErrReadingCSV, err)
+		field.Set(slice)
+		return nil
+	}
+
+	// cells encoded as JSON
+	if f.json {
+		if err := json.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
+			return errors.Join(ErrParsingType, err)
 		}
+		return nil
+	}
+
+	// database/sql nullable types
+	if isSQLNull(field.Type()) {
+		if value == "" {
+			field.SetZero()
+			return nil
+		}
+		if err := unmarshalField(field.Field(0), value, f); err != nil {
+			return err
+		}
+		field.Field(1).SetBool(true)
+		return nil
+	}
+
+	// time fields, with the format and tz tag options
+	if field.Type() == timeType {
+		t, err := f.parseTime(value)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	// encoding.TextUnmarshaler takes precedence over the kind, e.g. for enums based on integers
+	if field.Kind() != reflect.Ptr && field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	if f.hasNumberSeparators() && isNumberKind(field.Kind()) {
+		value = f.normalizeNumber(value)
 	}
-	return nil
-}
 
-// unmarshals a string value to a field
-// based on the type of the field
-func unmarshalField(field reflect.Value, value string) error {
 	switch field.Kind() {
 	// strings
 	case reflect.String:
 		field.SetString(value)
 	// integers
 	case reflect.Int:
-		i, err := strconv.Atoi(value)
+		i, err := strconv.ParseInt(value, f.base, 0)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
-		field.SetInt(int64(i))
+		field.SetInt(i)
 	case reflect.Int8:
-		i, err := strconv.ParseInt(value, 10, 8)
+		i, err := strconv.ParseInt(value, f.base, 8)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetInt(i)
 	case reflect.Int16:
-		i, err := strconv.ParseInt(value, 10, 16)
+		i, err := strconv.ParseInt(value, f.base, 16)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetInt(i)
 	case reflect.Int32:
-		i, err := strconv.ParseInt(value, 10, 32)
+		i, err := strconv.ParseInt(value, f.base, 32)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetInt(i)
 	case reflect.Int64:
-		i, err := strconv.ParseInt(value, 10, 64)
+		i, err := strconv.ParseInt(value, f.base, 64)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetInt(i)
 	// booleans
 	case reflect.Bool:
-		b, err := strconv.ParseBool(value)
+		b, err := f.parseBool(value)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
@@ -302,45 +1065,58 @@ func unmarshalField(field reflect.Value, value string) error {
 		field.SetFloat(f)
 	// unsigned integers
 	case reflect.Uint:
-		i, err := strconv.ParseUint(value, 10, 0)
+		i, err := strconv.ParseUint(value, f.base, 0)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetUint(i)
 	case reflect.Uint8:
-		i, err := strconv.ParseUint(value, 10, 8)
+		i, err := strconv.ParseUint(value, f.base, 8)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetUint(i)
 	case reflect.Uint16:
-		i, err := strconv.ParseUint(value, 10, 16)
+		i, err := strconv.ParseUint(value, f.base, 16)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetUint(i)
 	case reflect.Uint32:
-		i, err := strconv.ParseUint(value, 10, 32)
+		i, err := strconv.ParseUint(value, f.base, 32)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetUint(i)
 	case reflect.Uint64:
-		i, err := strconv.ParseUint(value, 10, 64)
+		i, err := strconv.ParseUint(value, f.base, 64)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		field.SetUint(i)
+	case reflect.Uintptr:
+		i, err := strconv.ParseUint(value, f.base, strconv.IntSize)
 		if err != nil {
 			return errors.Join(ErrParsingType, err)
 		}
 		field.SetUint(i)
+	// complex numbers
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(value, field.Type().Bits())
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		field.SetComplex(c)
 	case reflect.Ptr:
 		if field.IsNil() {
 			field.Set(reflect.New(field.Type().Elem()))
 		}
-		return unmarshalField(field.Elem(), value)
+		return unmarshalField(field.Elem(), value, f)
 	default:
 		if field.CanAddr() {
-			// check if the field implements encoding.TextUnmarshaler
-			if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
-				return u.UnmarshalText([]byte(value))
+			// check if the field implements sql.Scanner
+			if s, ok := field.Addr().Interface().(sql.Scanner); ok {
+				return s.Scan(value)
 			}
 		}
 		return errors.Join(ErrUnprocessableType, fmt.Errorf("type %s", field.Kind()))
@@ -350,41 +1126,98 @@ func unmarshalField(field reflect.Value, value string) error {
 
 // marshalField marshals a field to a string
 // based on the type of the field
-func marshalField(field reflect.Value) (string, error) {
+func marshalField(field reflect.Value, f *field) (string, error) {
+	// custom converters
+	if conv, ok := f.fieldConverter(field.Type()); ok {
+		return conv.format(field)
+	}
+
+	// cells encoded as JSON
+	if f.json {
+		b, err := json.Marshal(field.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	// database/sql nullable types
+	if isSQLNull(field.Type()) {
+		if !field.Field(1).Bool() {
+			return "", nil
+		}
+		return marshalField(field.Field(0), f)
+	}
+
+	// bytes encoded as text
+	if f.enc != "" && isBytes(field.Type()) {
+		return encodeBytes(field.Bytes(), f.enc), nil
+	}
+
+	// slices with a separator
+	if field.Kind() == reflect.Slice && f.sep != "" {
+		parts := make([]string, field.Len())
+		for i := range parts {
+			part, err := marshalField(field.Index(i), f)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, f.sep), nil
+	}
+
+	// time fields, with the format and tz tag options
+	if field.Type() == timeType {
+		return f.formatTime(field.Interface().(time.Time)), nil
+	}
+
+	// encoding.TextMarshaler takes precedence over the kind, e.g. for enums based on integers
+	if field.Kind() != reflect.Ptr && reflect.PointerTo(field.Type()).Implements(textMarshalerType) {
+		if m, ok := addressable(field).Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			if err != nil {
+				return "", err
+			}
+			return string(b), nil
+		}
+	}
+
 	switch field.Kind() {
 	// strings
 	case reflect.String:
 		return field.String(), nil
 	// integers
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return fmt.Sprintf("%d", field.Int()), nil
+		return strconv.FormatInt(field.Int(), f.formatBase()), nil
 	// booleans
 	case reflect.Bool:
-		return fmt.Sprintf("%t", field.Bool()), nil
+		return f.formatBool(field.Bool()), nil
 	// floats
 	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf("%f", field.Float()), nil
+		return f.localizeNumber(strconv.FormatFloat(field.Float(), 'f', 6, 64)), nil
 	// unsigned integers
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return fmt.Sprintf("%d", field.Uint()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(field.Uint(), f.formatBase()), nil
+	// complex numbers, with the shortest representation reading back the same value
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(field.Complex(), 'g', -1, field.Type().Bits()), nil
 	case reflect.Ptr:
 		if field.IsNil() {
 			return "", nil
 		}
-		return marshalField(field.Elem())
+		return marshalField(field.Elem(), f)
 	default:
-		// take pointer to the field
-		if field.CanAddr() {
-			field = field.Addr()
-		}
+		// methods with value and pointer receivers are both found on the pointer
+		field = addressable(field)
 
-		// check if the field implements encoding.TextMarshaler
-		if m, ok := field.Interface().(encoding.TextMarshaler); ok {
-			b, err := m.MarshalText()
+		// check if the field implements driver.Valuer
+		if v, ok := field.Interface().(driver.Valuer); ok {
+			value, err := v.Value()
 			if err != nil {
 				return "", err
 			}
-			return string(b), nil
+			return formatDriverValue(value)
 		}
 		// check if the field implements fmt.Stringer
 		if s, ok := field.Interface().(fmt.Stringer); ok {
@@ -394,6 +1227,48 @@ func marshalField(field reflect.Value) (string, error) {
 	}
 }
 
+// decodeBytes decodes a cell of a []byte field with the encoding of the enc tag option
+func decodeBytes(value, enc string) ([]byte, error) {
+	if enc == _ENC_HEX {
+		return hex.DecodeString(value)
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// encodeBytes encodes a []byte field with the encoding of the enc tag option
+func encodeBytes(b []byte, enc string) string {
+	if enc == _ENC_HEX {
+		return hex.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// addressable returns a pointer to v, or to a copy of v if it is not addressable
+// (e.g. a struct passed by value), so methods with pointer receivers are found
+func addressable(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		v = copied
+	}
+	return v.Addr()
+}
+
+// isTextType reports whether t implements encoding.TextMarshaler or
+// encoding.TextUnmarshaler, with value or pointer receivers
+func isTextType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
 // Errors
 var (
 	ErrUnsupportedTag      = fmt.Errorf("unsupported tag")
@@ -408,6 +1283,21 @@ var (
 	ErrEmptyValue          = fmt.Errorf("empty value")
 	ErrAliasNotFound       = fmt.Errorf("alias not found")
 	ErrWrongNumberOfFields = fmt.Errorf("wrong number of fields")
+	ErrInvalidConfig       = fmt.Errorf("invalid config")
+	ErrUnknownColumn       = fmt.Errorf("unknown column")
+	ErrDuplicateColumn     = fmt.Errorf("duplicate column")
+	ErrCharset             = fmt.Errorf("charset error")
+	ErrClosed              = fmt.Errorf("already closed")
+	ErrTooManyErrors       = fmt.Errorf("too many errors")
+	ErrValidation          = fmt.Errorf("validation failed")
+	ErrConstraintViolation = fmt.Errorf("constraint violation")
+	ErrNilRecord           = fmt.Errorf("nil record")
+	ErrUnquotableField     = fmt.Errorf("field needs quotes")
+	ErrTransform           = fmt.Errorf("transform failed")
+	ErrHeaderMismatch      = fmt.Errorf("header does not match")
+	ErrDuplicateRow        = fmt.Errorf("duplicate row")
+	ErrDuplicateValue      = fmt.Errorf("duplicate value")
+	ErrUnknownVariant      = fmt.Errorf("unknown variant")
 )
 
 const (
@@ -415,4 +1305,39 @@ const (
 	_TAG_OMITEMPTY = "omitempty"
 	_TAG_ALIAS     = "alias"
 	_TAG_SKIP      = "-"
+	_TAG_FLATTEN   = "flatten"
+	_TAG_SEP       = "sep"
+	_TAG_REST      = "rest"
+	_TAG_DEFAULT   = "default"
+	_TAG_REQUIRED  = "required"
+	_TAG_INDEX     = "index"
+	_TAG_CONV      = "conv"
+	_TAG_MIN       = "min"
+	_TAG_MAX       = "max"
+	_TAG_PATTERN   = "pattern"
+	_TAG_JSON      = "json"
+	_TAG_BASE      = "base"
+	_TAG_THOUSANDS = "thousands"
+	_TAG_DECIMAL   = "decimal"
+	_TAG_TRUE      = "true"
+	_TAG_FALSE     = "false"
+	_TAG_NULL      = "null"
+	_TAG_TRIM      = "trim"
+	_TAG_ENC       = "enc"
+	_TAG_FORMAT    = "format"
+	_TAG_TZ        = "tz"
+	_TAG_EMPTY     = "empty"
+	_TAG_UNIQUE    = "unique"
+
+	// encodings of []byte fields
+	_ENC_BASE64 = "base64"
+	_ENC_HEX    = "hex"
+
+	// separator between the prefix and the alias of flattened fields
+	_FLATTEN_SEPARATOR = "_"
+
+	// metadata fields, e.g. `csva:"-,source_file"`
+	_TAG_META_SOURCE_FILE = "source_file"
+	_TAG_META_BYTE_OFFSET = "byte_offset"
+	_TAG_META_ROW_HASH    = "row_hash"
 )
@@ -1,6 +1,14 @@
 package csvadapter
 
-import "encoding/csv"
+import (
+	"encoding/csv"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
 
 func newCSVAdapterOptions() *csvAdapterOptions {
 	return &csvAdapterOptions{
@@ -10,6 +18,7 @@ func newCSVAdapterOptions() *csvAdapterOptions {
 		// default other options
 		writeHeader:     true,
 		noImplicitAlias: false,
+		preserveOrder:   true,
 	}
 }
 
@@ -70,6 +79,226 @@ func UseCRLF(useCRLF bool) csvAdapterOption {
 	}
 }
 
+// sets the size of the buffer the input is read through
+//
+// larger buffers reduce the number of reads, e.g. on network filesystems and
+// object-store streams. (default: 4096)
+func ReadBufferSize(size int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.readBufferSize = size
+	}
+}
+
+// sets the size of the buffer the output is written through
+//
+// larger buffers reduce the number of writes to the underlying writer. With
+// compression or a charset, the output of those layers is buffered as well.
+// (default: 4096)
+func WriteBufferSize(size int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.writeBufferSize = size
+	}
+}
+
+// sets the location of timestamps without a zone
+//
+// time fields whose format has no zone are parsed in loc instead of UTC, and
+// written in loc. The tz tag option overrides it for a field.
+func Location(loc *time.Location) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.location = loc
+	}
+}
+
+// sets the write UTC flag
+//
+// when set to true, time fields are converted to UTC before being written,
+// whatever their location or the Location option.
+func WriteUTC(writeUTC bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.writeUTC = writeUTC
+	}
+}
+
+// sets the atomic write flag
+//
+// when set to true, ToFile writes to a temporary file in the same directory and
+// renames it over the destination once all the records are written, so readers
+// never see a partially written file. The mode of an existing destination is kept.
+func AtomicWrite(atomicWrite bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.atomicWrite = atomicWrite
+	}
+}
+
+// sets the number of lines skipped before the header
+//
+// the lines are discarded as they are, so preambles (e.g. titles or export
+// metadata) do not need to be valid csv.
+func SkipRows(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.skipRows = n
+	}
+}
+
+// sets the maximum number of rows read
+//
+// reading stops once n rows are decoded successfully, rows with errors are not
+// counted. 0 means no limit.
+func Limit(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.limit = n
+	}
+}
+
+// sets the number of rows skipped before reading
+//
+// the first n records after the header are discarded without being decoded,
+// line numbers of errors still count them.
+func Offset(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.offset = n
+	}
+}
+
+// sets the predicate of the rows kept when reading
+//
+// rows for which keep returns false are discarded by the adapter instead of
+// being yielded, they are not counted by the Limit option. T must be the type
+// of the adapter.
+func Filter[T any](keep func(item T) bool) csvAdapterOption {
+	filter := &rowFilter{
+		typ:  reflect.TypeFor[T](),
+		keep: func(item any) bool { return keep(item.(T)) },
+	}
+	return func(o *csvAdapterOptions) {
+		o.filter = filter
+	}
+}
+
+// sets the number of workers decoding the rows of FromCSV
+//
+// records are still read sequentially, but their fields are parsed by a pool of
+// workers, which pays off for large files and rows with many fields. Converters,
+// validators and the MapRead and Filter functions must be safe for concurrent use.
+// 0 or 1 decode the rows in the calling goroutine (default).
+func Parallel(workers int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.workers = workers
+	}
+}
+
+// sets the preserve order flag
+//
+// when set to false, FromCSV yields the rows decoded in parallel as soon as
+// they are ready instead of in the order of the file. (default: true)
+func PreserveOrder(preserveOrder bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.preserveOrder = preserveOrder
+	}
+}
+
+// sets the transformation applied to the rows when reading
+//
+// fn is called on every decoded row before the Filter option, the row it returns
+// is yielded instead. Errors are reported as row errors wrapping ErrTransform.
+// Several transformations can be chained with Pipe. T must be the type of the adapter.
+func MapRead[T any](fn func(item T) (T, error)) csvAdapterOption {
+	mapper := newRowMapper(fn)
+	return func(o *csvAdapterOptions) {
+		o.mapRead = mapper
+	}
+}
+
+// sets the transformation applied to the rows when writing
+//
+// fn is called on every row before it is encoded, the row it returns is written
+// instead. Errors wrap ErrTransform. T must be the type of the adapter.
+func MapWrite[T any](fn func(item T) (T, error)) csvAdapterOption {
+	mapper := newRowMapper(fn)
+	return func(o *csvAdapterOptions) {
+		o.mapWrite = mapper
+	}
+}
+
+// Pipe chains transformations into one, e.g. for MapRead and MapWrite
+//
+// the transformations are applied in order, the first error stops the chain.
+func Pipe[T any](fns ...func(item T) (T, error)) func(item T) (T, error) {
+	return func(item T) (T, error) {
+		for _, fn := range fns {
+			var err error
+			if item, err = fn(item); err != nil {
+				return item, err
+			}
+		}
+		return item, nil
+	}
+}
+
+// sets the predicate of the records kept when reading
+//
+// unlike Filter, the raw record is checked before being decoded, so discarded
+// rows are neither parsed nor validated.
+func FilterRecord(keep func(record []string) bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.filterRecord = keep
+	}
+}
+
+// sets the number of lines dropped at the end of the file
+//
+// footers (e.g. "Total: 1234" rows of bank exports) are dropped before decoding,
+// so they do not surface as row errors.
+func SkipFooter(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.skipFooter = n
+	}
+}
+
+// sets the predicate of the records dropped at the end of the file
+//
+// the records matching skip after the last record not matching it are dropped,
+// after the lines of the SkipFooter option. The record may be partial or nil if
+// the row is malformed.
+func SkipFooterFunc(skip func(record []string) bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.skipFooterFunc = skip
+	}
+}
+
+// sets the trim space flag
+//
+// when set to true, leading and trailing whitespace is trimmed from the cells
+// before parsing, so whitespace-only cells are empty. The trim tag option
+// enables it for a single field.
+func TrimSpace(trimSpace bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.trimSpace = trimSpace
+	}
+}
+
+// sets the representation of nil values, e.g. "\\N" for PostgreSQL COPY
+//
+// nil pointers and invalid database/sql nullable values are written as null,
+// and cells equal to null are read back as nil (or as empty cells for other types).
+// The null tag option overrides it for a single field. It can be passed to a
+// single read or write, e.g. with WithDialect(PostgresDialect).
+func NullString(null string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.nullString = null
+	}
+}
+
+// sets the quote policy of ToCSV
+//
+// more info: QuotePolicy
+func Quoting(policy QuotePolicy) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.quoting = policy
+	}
+}
+
 // sets the write header flag
 //
 // when set to true, the header will be written when calling ToCSV
@@ -79,6 +308,119 @@ func WriteHeader(writeHeader bool) csvAdapterOption {
 	}
 }
 
+// sets the write BOM flag
+//
+// when set to true, ToCSV starts the output with a UTF-8 byte order mark,
+// which Excel needs to detect the encoding. A leading BOM is always stripped by FromCSV.
+// As the mark is UTF-8, it cannot be combined with the Charset option: adapters
+// with both are rejected with ErrInvalidConfig.
+func WriteBOM(writeBOM bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.writeBOM = writeBOM
+	}
+}
+
+// sets the charset transcoders
+//
+// decode wraps the input of FromCSV to convert it to UTF-8, and encode wraps the
+// output of ToCSV to convert it from UTF-8. If the writer returned by encode
+// implements io.Closer, it is closed once the csv data is written.
+// Transcoders of golang.org/x/text/encoding plug in directly, e.g.
+//
+//	Charset(charmap.Windows1252.NewDecoder().Reader, charmap.Windows1252.NewEncoder().Writer)
+func Charset(decode func(io.Reader) io.Reader, encode func(io.Writer) io.Writer) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.decodeCharset = decode
+		o.encodeCharset = encode
+	}
+}
+
+// sets the compression format
+//
+// more info: Compression
+func WithCompression(compression Compression) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.compression = compression
+	}
+}
+
+// sets the functions used to parse and format the values of type V
+//
+// unlike RegisterConverter, the converter only applies to this adapter
+// and takes precedence over registered converters.
+func Converter[V any](parse func(string) (V, error), format func(V) (string, error)) csvAdapterOption {
+	conv := newConverter(parse, format)
+	return func(o *csvAdapterOptions) {
+		if o.converters == nil {
+			o.converters = make(map[reflect.Type]converter)
+		}
+		o.converters[reflect.TypeFor[V]()] = conv
+	}
+}
+
+// registers a named converter for values of type V
+//
+// fields select it with the conv tag option, e.g. `csva:"code,conv=upper"`,
+// so fields of the same type can be parsed and formatted differently.
+func WithConverter[V any](name string, parse func(string) (V, error), format func(V) (string, error)) csvAdapterOption {
+	conv := newConverter(parse, format)
+	return func(o *csvAdapterOptions) {
+		if o.namedConverters == nil {
+			o.namedConverters = make(map[string]converter)
+		}
+		o.namedConverters[name] = conv
+	}
+}
+
+// sets the representations of booleans
+//
+// when reading, the values are accepted (regardless of their case) besides
+// the literals of strconv.ParseBool. When writing, the first values are used.
+// The `true=` and `false=` tag options override them per field.
+func BoolValues(trueValues, falseValues []string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.trueValues = trueValues
+		o.falseValues = falseValues
+	}
+}
+
+// sets the separators of numbers
+//
+// when reading, thousands separators are removed and the decimal separator is
+// replaced by ".", e.g. NumberSeparators('.', ',') reads "1.234,56" as 1234.56.
+// When writing, floats use the decimal separator (without thousands separators).
+// Use 0 as thousands separator to disable it. The `thousands=` and `decimal=`
+// tag options override them per field.
+func NumberSeparators(thousands, decimal rune) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.thousands = thousands
+		o.decimal = decimal
+	}
+}
+
+// sets the aliases of fields, overriding their tags
+//
+// the keys are the names of the fields in the struct (e.g. "Email", or
+// "Address.City" for flattened structs), so one struct can map files whose
+// headers differ. Unknown fields make NewCSVAdapter fail with ErrFieldNotFound.
+func AliasOverrides(aliases map[string]string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.aliasOverrides = aliases
+	}
+}
+
+// sets the order of the columns
+//
+// ToCSV writes the listed columns first, in the given order, followed by the
+// other fields in declaration order. Without a header, FromCSV expects the
+// same order. Unknown columns make NewCSVAdapter fail with ErrUnknownColumn.
+// Positional fields keep their index.
+func ColumnOrder(aliases ...string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.columnOrder = aliases
+	}
+}
+
 // sets the no implicit alias flag
 //
 // when set to true, field names will not be used as aliases when not specified.
@@ -88,6 +430,225 @@ func NoImplicitAlias(noImplicitAlias bool) csvAdapterOption {
 	}
 }
 
+// sets the style of implicit aliases
+//
+// more info: AliasStyle
+func ImplicitAliasStyle(style AliasStyle) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.implicitAliasStyle = style
+	}
+}
+
+// sets the no header flag
+//
+// when set to true, FromCSV treats the first line as data and maps the columns
+// by the order of the fields (or by their positional `index` tags).
+func NoHeader(noHeader bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.noHeader = noHeader
+	}
+}
+
+// sets the case insensitive header flag
+//
+// when set to true, header names are matched to aliases regardless of their case.
+func CaseInsensitiveHeader(caseInsensitive bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.caseInsensitiveHeader = caseInsensitive
+	}
+}
+
+// sets the header normalizer
+//
+// header names and aliases are matched after being normalized, e.g. with
+// NormalizeHeader "First Name" matches a field aliased first_name.
+func HeaderNormalizer(normalizer func(name string) string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.headerNormalizer = normalizer
+	}
+}
+
+// sets the strict header flag
+//
+// when set to true, FromCSV returns an error listing the columns of the csv
+// that are not mapped to any field (unless a `rest` field collects them).
+func StrictHeader(strictHeader bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.strictHeader = strictHeader
+	}
+}
+
+// sets the allow missing columns flag
+//
+// when set to true, the fields whose column is not in the header are left at
+// their zero value instead of failing with ErrFieldNotFound, without changing
+// how empty cells are read (unlike omitempty). Required fields must still be present.
+func AllowMissingColumns(allowMissingColumns bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.allowMissingColumns = allowMissingColumns
+	}
+}
+
+// sets the allow short rows flag
+//
+// when set to true, the rows with fewer cells than the header (e.g. without
+// their trailing commas) are read as if the missing cells were empty, so the
+// omitempty and default tag options apply. Rows with more cells are still rejected.
+func AllowShortRows(allowShortRows bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.allowShortRows = allowShortRows
+	}
+}
+
+// sets the number of records after which ToCSV and Encoder flush the output
+//
+// the error of the writer is checked after each flush, so writing stops as
+// soon as the output fails (e.g. a full disk or a closed pipe) and streamed
+// output reaches the reader in batches. 0 (the default) flushes when closing.
+func FlushEvery(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.flushEvery = n
+	}
+}
+
+// sets the collect stats flag
+//
+// when set to true, the decoder collects metrics of each column while reading
+// (non-empty cells, range of the numbers, estimated number of distinct values),
+// returned by Decoder.ColumnStats. Records dropped by FilterRecord are not counted.
+func CollectStats(collectStats bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.collectStats = collectStats
+	}
+}
+
+// sets the name of the row hash column
+//
+// when set, ToCSV and Encoder write the hash of each row in an extra last
+// column: the hex SHA-256 of the formatted cells of its fields, the value of
+// a `csva:"-,row_hash"` field when the row is read back. The column is
+// expected by AppendToFile and is not an unknown column when reading.
+// "" (the default) writes no hash.
+func RowHashColumn(name string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.rowHashColumn = name
+	}
+}
+
+// sets the byte offset to resume reading at
+//
+// the header is read, then the input is skipped up to offset, which must be
+// the offset of a record, e.g. Decoder.InputOffset saved while importing a
+// previous time. Line numbers are counted from offset, byte offsets from the
+// start of the input.
+func ResumeAt(offset int64) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.resumeAt = offset
+	}
+}
+
+// sets the close reader flag
+//
+// when set to true, the reader passed to FromCSV or NewDecoder is closed if it
+// is an io.Closer (e.g. a file or a response body): once the rows are
+// exhausted or the loop over them is stopped, when Decoder.Close is called, or
+// when the decoder cannot be created.
+func CloseReader(closeReader bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.closeReader = closeReader
+	}
+}
+
+// DuplicateHeaderPolicy defines how FromCSV handles a header containing the same column more than once
+type DuplicateHeaderPolicy int
+
+const (
+	DuplicateLastWins   DuplicateHeaderPolicy = iota // the last column is bound (default)
+	DuplicateFirstWins                               // the first column is bound
+	DuplicateError                                   // FromCSV returns ErrDuplicateColumn
+	DuplicateSequential                              // the columns are bound to successive fields with the same alias
+)
+
+// sets the duplicate header policy
+//
+// more info: DuplicateHeaderPolicy
+func DuplicateHeaders(policy DuplicateHeaderPolicy) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.duplicateHeaders = policy
+	}
+}
+
+// sets the maximum number of row errors
+//
+// once n rows failed, the n-th error is joined with ErrTooManyErrors and
+// reading stops. The error is returned even with the SkipInvalidRows and
+// OnRowError options, so a truncated read is not mistaken for the end of the
+// input. 0 (the default) means no limit.
+func MaxErrors(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.maxErrors = n
+	}
+}
+
+// sets the skip invalid rows flag
+//
+// when set to true, FromCSV and Decode silently skip the rows that fail,
+// Decoder.Skipped returns how many were skipped.
+func SkipInvalidRows(skipInvalidRows bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.skipInvalidRows = skipInvalidRows
+	}
+}
+
+// sets the row error callback
+//
+// when set, FromCSV and Decode pass the errors of invalid rows to the callback
+// (with the line and a copy of the record, nil if it could not be parsed) instead of
+// returning them, so only valid rows are yielded. Returning false stops reading.
+func OnRowError(callback func(line int, record []string, err error) bool) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.onRowError = callback
+	}
+}
+
+// sets the error renderer
+//
+// the renderer is used by RenderError to translate errors into user-facing messages,
+// e.g. in another language or with field labels instead of Go names.
+func RenderErrors(renderer ErrorRenderer) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.errorRenderer = renderer
+	}
+}
+
+// sets the options described by a dialect
+//
+// the flags of the csv layer (LazyQuotes, TrimLeadingSpace and UseCRLF) are
+// all applied, false turning them off; the other fields left to their zero
+// value do not change the options. Options passed after WithDialect override the dialect.
+func WithDialect(d Dialect) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		if d.Comma != 0 {
+			o.comma = d.Comma
+		}
+		if d.Comment != 0 {
+			o.comment = d.Comment
+		}
+		o.lazyQuotes = d.LazyQuotes
+		o.trimLeadingSpace = d.TrimLeadingSpace
+		o.useCRLF = d.UseCRLF
+		if d.NoHeader {
+			o.noHeader, o.writeHeader = true, false
+		}
+		if d.Null != "" {
+			o.nullString = d.Null
+		}
+		if d.Quoting != QuoteMinimal {
+			o.quoting = d.Quoting
+		}
+	}
+}
+
 type csvAdapterOptions struct {
 	// encoding/csv options
 	comma            rune
@@ -100,6 +661,113 @@ type csvAdapterOptions struct {
 	// other options
 	writeHeader     bool
 	noImplicitAlias bool
+	noHeader        bool
+	errorRenderer   ErrorRenderer
+	nullString      string
+
+	caseInsensitiveHeader bool
+	strictHeader          bool
+	allowMissingColumns   bool
+	duplicateHeaders      DuplicateHeaderPolicy
+	writeBOM              bool
+	decodeCharset         func(io.Reader) io.Reader
+	encodeCharset         func(io.Writer) io.Writer
+	compression           Compression
+	maxErrors             int
+	onRowError            func(line int, record []string, err error) bool
+	skipInvalidRows       bool
+	converters            map[reflect.Type]converter
+	namedConverters       map[string]converter
+	trueValues            []string
+	falseValues           []string
+	thousands             rune
+	decimal               rune
+	columnOrder           []string
+	aliasOverrides        map[string]string
+	headerNormalizer      func(name string) string
+	implicitAliasStyle    AliasStyle
+	quoting               QuotePolicy
+	trimSpace             bool
+	skipRows              int
+	skipFooter            int
+	skipFooterFunc        func(record []string) bool
+	limit                 int
+	offset                int
+	filter                *rowFilter
+	filterRecord          func(record []string) bool
+	mapRead               *rowMapper
+	mapWrite              *rowMapper
+	workers               int
+	preserveOrder         bool
+	readBufferSize        int
+	writeBufferSize       int
+	atomicWrite           bool
+	location              *time.Location
+	writeUTC              bool
+	dedupe                *dedupeKey
+	uniqueLimit           int
+	allowShortRows        bool
+	closeReader           bool
+	resumeAt              int64
+	flushEvery            int
+	collectStats          bool
+	rowHashColumn         string
+	sqlSyntax             SQLSyntax
+}
+
+// rowFilter is the predicate of the Filter option
+type rowFilter struct {
+	typ  reflect.Type // type of the rows
+	keep func(item any) bool
+}
+
+// rowMapper is the transformation of the MapRead and MapWrite options
+type rowMapper struct {
+	typ reflect.Type // type of the rows
+	fn  func(item any) (any, error)
+}
+
+// newRowMapper wraps a typed transformation into a rowMapper
+func newRowMapper[T any](fn func(item T) (T, error)) *rowMapper {
+	return &rowMapper{
+		typ: reflect.TypeFor[T](),
+		fn: func(item any) (any, error) {
+			return fn(item.(T))
+		},
+	}
+}
+
+// clone returns a copy of the options not sharing their maps and slices
+func (c csvAdapterOptions) clone() *csvAdapterOptions {
+	c.converters = maps.Clone(c.converters)
+	c.namedConverters = maps.Clone(c.namedConverters)
+	c.aliasOverrides = maps.Clone(c.aliasOverrides)
+	c.trueValues = slices.Clone(c.trueValues)
+	c.falseValues = slices.Clone(c.falseValues)
+	c.columnOrder = slices.Clone(c.columnOrder)
+	if c.dedupe != nil {
+		dedupe := *c.dedupe
+		dedupe.aliases = slices.Clone(dedupe.aliases)
+		c.dedupe = &dedupe
+	}
+	return &c
+}
+
+// fieldOptions returns the options describing the fields, which are fixed when the adapter is created
+func (c csvAdapterOptions) fieldOptions() map[string]any {
+	return map[string]any{
+		"NoImplicitAlias":    c.noImplicitAlias,
+		"ImplicitAliasStyle": c.implicitAliasStyle,
+		"AliasOverrides":     c.aliasOverrides,
+		"ColumnOrder":        c.columnOrder,
+		"Converter":          c.converters,
+		"WithConverter":      c.namedConverters,
+		"BoolValues":         c.trueValues,
+		"NumberSeparators":   c.thousands != 0 || c.decimal != 0,
+		"TrimSpace":          c.trimSpace,
+		"Location":           c.location,
+		"WriteUTC":           c.writeUTC,
+	}
 }
 
 func (c csvAdapterOptions) applyReader(reader *csv.Reader) {
@@ -108,9 +776,24 @@ func (c csvAdapterOptions) applyReader(reader *csv.Reader) {
 	reader.LazyQuotes = c.lazyQuotes
 	reader.TrimLeadingSpace = c.trimLeadingSpace
 	reader.ReuseRecord = c.reuseRecord
+	if c.allowShortRows {
+		// the number of cells is checked by the decoder
+		reader.FieldsPerRecord = -1
+	}
 }
 
 func (c csvAdapterOptions) applyWriter(writer *csv.Writer) {
 	writer.Comma = c.comma
 	writer.UseCRLF = c.useCRLF
 }
+
+// headerKey returns the key used to match a header name to an alias
+func (c csvAdapterOptions) headerKey(name string) string {
+	if c.headerNormalizer != nil {
+		name = c.headerNormalizer(name)
+	}
+	if c.caseInsensitiveHeader {
+		return strings.ToLower(name)
+	}
+	return name
+}
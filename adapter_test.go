@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +110,124 @@ func TestNewCSVAdapterSkipField(t *testing.T) {
 	}
 }
 
+func TestFromCSVMetaFields(t *testing.T) {
+	type PersonWithSource struct {
+		Name   string `csva:"name"`
+		Age    int    `csva:"age"`
+		Source string `csva:"-,source_file"`
+		Offset int64  `csva:"-,byte_offset"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithSource]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if len(adapter.fields) != 2 || len(adapter.metaFields) != 2 {
+		t.Fatalf("expected 2 fields and 2 meta fields, got %d and %d", len(adapter.fields), len(adapter.metaFields))
+	}
+
+	csvData := "name,age\nJohn Doe,30\nJane Smith,25\n"
+	reader := namedReader{bytes.NewReader([]byte(csvData)), "people.csv"}
+	people, err := adapter.FromCSV(reader)
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+
+	expected := []PersonWithSource{
+		{"John Doe", 30, "people.csv", 9},
+		{"Jane Smith", 25, "people.csv", 21},
+	}
+	idx := 0
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != expected[idx] {
+			t.Errorf("expected %+v, got %+v", expected[idx], person)
+		}
+		idx++
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(expected)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %s, got %s", csvData, writer.String())
+	}
+
+	type WrongMetaType struct {
+		Offset string `csva:"-,byte_offset"`
+	}
+	if _, err := NewCSVAdapter[WrongMetaType](); !errors.Is(err, ErrUnprocessableType) {
+		t.Errorf("expected ErrUnprocessableType, got %v", err)
+	}
+
+	type UnknownMeta struct {
+		Name string `csva:"-,foo"`
+	}
+	if _, err := NewCSVAdapter[UnknownMeta](); !errors.Is(err, ErrUnsupportedTag) {
+		t.Errorf("expected ErrUnsupportedTag, got %v", err)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	type Address struct {
+		Street string `csva:"street"`
+		City   string `csva:"city"`
+	}
+	type PersonWithAddress struct {
+		Name    string   `csva:"name"`
+		Addr    Address  `csva:"addr,flatten"`
+		Billing *Address `csva:"billing,flatten,omitempty"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithAddress]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `name,addr_street,addr_city,billing_street,billing_city
+John Doe,Main St,Springfield,Elm St,Shelbyville
+Jane Smith,High St,Capital City,,
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	result := make([]PersonWithAddress, 0)
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		result = append(result, person)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 people, got %d", len(result))
+	}
+	if result[0].Addr.City != "Springfield" || result[0].Billing == nil || result[0].Billing.Street != "Elm St" {
+		t.Errorf("unexpected first person %+v", result[0])
+	}
+	if result[1].Addr.Street != "High St" || result[1].Billing != nil {
+		t.Errorf("unexpected second person %+v", result[1])
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(result)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %s, got %s", csvData, writer.String())
+	}
+
+	type WrongFlatten struct {
+		Name string `csva:"name,flatten"`
+	}
+	if _, err := NewCSVAdapter[WrongFlatten](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
 func TestNewCSVAdapterWrongType(t *testing.T) {
 	_, err := NewCSVAdapter[string]()
 	if err == nil {
@@ -591,16 +711,1301 @@ Jane Smith,0
 	}
 }
 
-// Test data
-const (
-	fakemail      = "fakemail@mail.com"
-	otherfakemail = "otherfakenail@mail.com"
-	name          = "John Doe"
-	othername     = "Jane Smith"
-	age           = 30
-	otherage      = 25
-)
+func TestSliceFields(t *testing.T) {
+	type Product struct {
+		Name   string   `csva:"name"`
+		Tags   []string `csva:"tags,sep=;"`
+		Sizes  []int    `csva:"sizes,sep=|,omitempty"`
+		Prices *[]int   `csva:"prices,sep=|,omitempty"`
+	}
 
-func stringPtr(s string) *string {
-	return &s
+	adapter, err := NewCSVAdapter[Product]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `name,tags,sizes,prices
+Shirt,red;cotton,1|2|3,10|20
+Hat,blue,,
+`
+	products, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	result := make([]Product, 0)
+	for product, err := range products {
+		if err != nil {
+			t.Fatalf("failed to read product: %v", err)
+		}
+		result = append(result, product)
+	}
+	if !slices.Equal(result[0].Tags, []string{"red", "cotton"}) ||
+		!slices.Equal(result[0].Sizes, []int{1, 2, 3}) ||
+		!slices.Equal(*result[0].Prices, []int{10, 20}) {
+		t.Errorf("unexpected first product %+v", result[0])
+	}
+	if !slices.Equal(result[1].Tags, []string{"blue"}) || result[1].Sizes != nil || result[1].Prices != nil {
+		t.Errorf("unexpected second product %+v", result[1])
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(result)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %s, got %s", csvData, writer.String())
+	}
+
+	type WrongSep struct {
+		Name string `csva:"name,sep=;"`
+	}
+	if _, err := NewCSVAdapter[WrongSep](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestRestField(t *testing.T) {
+	type PersonWithRest struct {
+		Name  string            `csva:"name"`
+		Age   int               `csva:"age"`
+		Extra map[string]string `csva:",rest"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithRest]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `name,age,city,country
+John Doe,30,Springfield,US
+Jane Smith,25,,UK
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	result := make([]PersonWithRest, 0)
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		result = append(result, person)
+	}
+	if result[0].Extra["city"] != "Springfield" || result[1].Extra["country"] != "UK" || len(result[1].Extra) != 2 {
+		t.Errorf("unexpected rest columns %+v", result)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(result)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %s, got %s", csvData, writer.String())
+	}
+
+	t.Run("unknown column", func(t *testing.T) {
+		result[1].Extra["zip"] = "12345"
+		err := adapter.ToCSV(&bytes.Buffer{}, slices.Values(result))
+		if !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("expected ErrUnknownColumn, got %v", err)
+		}
+	})
+
+	t.Run("no records", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		if err := adapter.ToCSV(writer, slices.Values([]PersonWithRest{})); err != nil {
+			t.Fatalf("failed to write CSV: %v", err)
+		}
+		if writer.String() != "name,age\n" {
+			t.Errorf("expected header only, got %s", writer.String())
+		}
+	})
+
+	type WrongRest struct {
+		Extra map[string]int `csva:",rest"`
+	}
+	if _, err := NewCSVAdapter[WrongRest](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	type PersonWithDefaults struct {
+		Name    string `csva:"name"`
+		Age     int    `csva:"age,default=18"`
+		Country string `csva:"country,default=US"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithDefaults]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `name,age
+John Doe,30
+Jane Smith,
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := []PersonWithDefaults{
+		{"John Doe", 30, "US"},
+		{"Jane Smith", 18, "US"},
+	}
+	idx := 0
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != expected[idx] {
+			t.Errorf("expected %+v, got %+v", expected[idx], person)
+		}
+		idx++
+	}
+
+	type WrongDefault struct {
+		Age int `csva:"age,default=old"`
+	}
+	if _, err := NewCSVAdapter[WrongDefault](); !errors.Is(err, ErrInvalidTag) || !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrInvalidTag and ErrParsingType, got %v", err)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	type PersonWithRequired struct {
+		Name  string `csva:"name"`
+		Email string `csva:"email,omitempty,required"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithRequired]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := adapter.FromCSV(bytes.NewReader([]byte("name\nJohn Doe\n")))
+		if !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("expected ErrFieldNotFound, got %v", err)
+		}
+	})
+
+	t.Run("empty cell", func(t *testing.T) {
+		people, err := adapter.FromCSV(bytes.NewReader([]byte("name,email\nJohn Doe,\n")))
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		for _, err := range people {
+			if !errors.Is(err, ErrEmptyValue) {
+				t.Errorf("expected ErrEmptyValue, got %v", err)
+			}
+		}
+	})
+
+	t.Run("omitempty on write", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		err := adapter.ToCSV(writer, slices.Values([]PersonWithRequired{{name, ""}}))
+		if err != nil {
+			t.Fatalf("failed to write CSV: %v", err)
+		}
+		if writer.String() != "name,email\nJohn Doe,\n" {
+			t.Errorf("unexpected CSV %s", writer.String())
+		}
+	})
+
+	type RequiredWithDefault struct {
+		Age int `csva:"age,required,default=1"`
+	}
+	if _, err := NewCSVAdapter[RequiredWithDefault](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestPositionalFields(t *testing.T) {
+	type PersonPositional struct {
+		Name  string `csva:"index=0"`
+		Email string `csva:"index=2,omitempty"`
+		Age   int    `csva:"index=1"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonPositional]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `John Doe,30,` + fakemail + `
+Jane Smith,25,
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := []PersonPositional{
+		{name, fakemail, age},
+		{othername, "", otherage},
+	}
+	idx := 0
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != expected[idx] {
+			t.Errorf("expected %+v, got %+v", expected[idx], person)
+		}
+		idx++
+	}
+	if idx != 2 {
+		t.Errorf("expected 2 people, got %d", idx)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(expected)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %s, got %s", csvData, writer.String())
+	}
+
+	t.Run("invalid tags", func(t *testing.T) {
+		type Mixed struct {
+			Name string `csva:"index=0"`
+			Age  int    `csva:"age"`
+		}
+		type Duplicate struct {
+			Name string `csva:"index=0"`
+			Age  int    `csva:"index=0"`
+		}
+		type Negative struct {
+			Name string `csva:"index=-1"`
+		}
+		for _, err := range []error{
+			second(NewCSVAdapter[Mixed]()),
+			second(NewCSVAdapter[Duplicate]()),
+			second(NewCSVAdapter[Negative]()),
+		} {
+			if !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("expected ErrInvalidTag, got %v", err)
+			}
+		}
+	})
+}
+
+func TestNoHeader(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](NoHeader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `John Doe,30,` + fakemail + `
+Jane Smith,25,
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := []Person{
+		{name, age, fakemail},
+		{othername, otherage, ""},
+	}
+	idx := 0
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != expected[idx] {
+			t.Errorf("expected %+v, got %+v", expected[idx], person)
+		}
+		idx++
+	}
+	if idx != len(expected) {
+		t.Errorf("expected %d people, got %d", len(expected), idx)
+	}
+
+	t.Run("short record", func(t *testing.T) {
+		adapter, err := NewCSVAdapter[Person](NoHeader(true))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		people, err := adapter.FromCSV(bytes.NewReader([]byte("John Doe\n")))
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		for _, err := range people {
+			if !errors.Is(err, ErrFieldNotFound) {
+				t.Errorf("expected ErrFieldNotFound, got %v", err)
+			}
+		}
+	})
+}
+
+func TestCaseInsensitiveHeader(t *testing.T) {
+	csvData := `NAME,Age,eMail
+John Doe,30,` + fakemail + `
+`
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := adapter.FromCSV(bytes.NewReader([]byte(csvData))); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+
+	adapter, err = NewCSVAdapter[Person](CaseInsensitiveHeader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if expected := (Person{name, age, fakemail}); person != expected {
+			t.Errorf("expected %+v, got %+v", expected, person)
+		}
+	}
+}
+
+func TestStrictHeader(t *testing.T) {
+	csvData := `name,age,email,city,country
+John Doe,30,` + fakemail + `,Springfield,US
+`
+	adapter, err := NewCSVAdapter[Person](StrictHeader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	_, err = adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "city, country") {
+		t.Errorf("expected error to list the unknown columns, got %v", err)
+	}
+
+	if _, err := adapter.FromCSV(bytes.NewReader([]byte("name,age,email\n"))); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestDuplicateHeaders(t *testing.T) {
+	type Contact struct {
+		Name   string `csva:"name"`
+		Phone  string `csva:"phone"`
+		Phone2 string `csva:"phone,omitempty"`
+	}
+	csvData := `name,phone,phone
+John Doe,111,222
+`
+	tests := []struct {
+		policy   DuplicateHeaderPolicy
+		expected Contact
+	}{
+		{DuplicateLastWins, Contact{name, "222", "222"}},
+		{DuplicateFirstWins, Contact{name, "111", "111"}},
+		{DuplicateSequential, Contact{name, "111", "222"}},
+	}
+	for _, tt := range tests {
+		adapter, err := NewCSVAdapter[Contact](DuplicateHeaders(tt.policy))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		contacts, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		for contact, err := range contacts {
+			if err != nil {
+				t.Fatalf("failed to read contact: %v", err)
+			}
+			if contact != tt.expected {
+				t.Errorf("policy %d: expected %+v, got %+v", tt.policy, tt.expected, contact)
+			}
+		}
+	}
+
+	adapter, err := NewCSVAdapter[Contact](DuplicateHeaders(DuplicateError))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := adapter.FromCSV(bytes.NewReader([]byte(csvData))); !errors.Is(err, ErrDuplicateColumn) {
+		t.Errorf("expected ErrDuplicateColumn, got %v", err)
+	}
+}
+
+func TestMarshalAll(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, people); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	got, err := adapter.UnmarshalAll(bytes.NewReader(writer.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if !slices.Equal(got, people) {
+		t.Errorf("expected %+v, got %+v", people, got)
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,email\nJohn Doe,invalid," + fakemail + "\n"))
+	if !errors.Is(err, ErrProcessingCSVLines) {
+		t.Errorf("expected ErrProcessingCSVLines, got %v", err)
+	}
+}
+
+func TestUnmarshalAllLenient(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,email\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		",30," + fakemail + "\n"
+	people, err := adapter.UnmarshalAllLenient(strings.NewReader(data))
+	expected := []Person{{othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+	var rowErrs *RowErrors
+	if !errors.As(err, &rowErrs) {
+		t.Fatalf("expected *RowErrors, got %v", err)
+	}
+	readingErrs := rowErrs.ReadingErrors()
+	if len(readingErrs) != 2 || readingErrs[0].Line != 1 || readingErrs[1].Line != 3 {
+		t.Errorf("expected errors on lines 1 and 3, got %+v", readingErrs)
+	}
+	if !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected ErrEmptyValue, got %v", err)
+	}
+
+	people, err = adapter.UnmarshalAllLenient(strings.NewReader("name,age,email\nJohn Doe,30," + fakemail + "\n"))
+	if err != nil || len(people) != 1 {
+		t.Errorf("expected 1 person and no error, got %+v, %v", people, err)
+	}
+}
+
+func TestBoolValues(t *testing.T) {
+	type Flags struct {
+		Active  bool  `csva:"active,true=yes|y,false=no|n"`
+		Deleted *bool `csva:"deleted,omitempty"`
+		Admin   bool  `csva:"admin"`
+	}
+	adapter, err := NewCSVAdapter[Flags](BoolValues([]string{"on"}, []string{"off"}))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("active,deleted,admin\nY,ON,true\nno,,Off\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 2 || !got[0].Active || !*got[0].Deleted || !got[0].Admin || got[1].Active || got[1].Deleted != nil || got[1].Admin {
+		t.Errorf("unexpected flags %+v", got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "active,deleted,admin\nyes,on,on\nno,,off\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("active,deleted,admin\nmaybe,,on\n")); !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+
+	type WrongBool struct {
+		Name string `csva:"name,true=yes"`
+	}
+	if _, err := NewCSVAdapter[WrongBool](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestIntegerBase(t *testing.T) {
+	type Device struct {
+		ID    uint32 `csva:"id,base=16"`
+		Mode  int    `csva:"mode,base=8"`
+		Mask  uint8  `csva:"mask,base=2"`
+		Flags int64  `csva:"flags,base=0"`
+	}
+	adapter, err := NewCSVAdapter[Device]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("id,mode,mask,flags\nDEADbeef,755,101,0x1f\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := []Device{{0xdeadbeef, 0o755, 0b101, 0x1f}}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != "id,mode,mask,flags\ndeadbeef,755,101,31\n" {
+		t.Errorf("unexpected csv %q", writer.String())
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("id,mode,mask,flags\nDEADbeef,9,101,0\n")); !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+
+	type WrongBase struct {
+		Name string `csva:"name,base=16"`
+	}
+	if _, err := NewCSVAdapter[WrongBase](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestJSONCells(t *testing.T) {
+	type Meta struct {
+		Source string `json:"source"`
+		Score  int    `json:"score"`
+	}
+	type Event struct {
+		Name   string            `csva:"name"`
+		Meta   Meta              `csva:"meta,json"`
+		Labels map[string]string `csva:"labels,json,omitempty"`
+		Tags   []string          `csva:"tags,json"`
+	}
+	adapter, err := NewCSVAdapter[Event]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	events := []Event{{"login", Meta{"web", 3}, map[string]string{"env": "prod"}, []string{"a", "b"}}}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, events); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name,meta,labels,tags\nlogin,\"{\"\"source\"\":\"\"web\"\",\"\"score\"\":3}\",\"{\"\"env\"\":\"\"prod\"\"}\",\"[\"\"a\"\",\"\"b\"\"]\"\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader(expected))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0].Meta != events[0].Meta || got[0].Labels["env"] != "prod" || !slices.Equal(got[0].Tags, events[0].Tags) {
+		t.Errorf("expected %+v, got %+v", events, got)
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("name,meta,labels,tags\nlogin,{,,[]\n")); !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+}
+
+func TestPointerAdapter(t *testing.T) {
+	adapter, err := NewCSVAdapter[*Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []*Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	got, err := adapter.UnmarshalAll(bytes.NewReader(writer.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 2 || *got[0] != *people[0] || *got[1] != *people[1] {
+		t.Errorf("expected %+v, got %+v", people, got)
+	}
+
+	err = adapter.ToCSV(&bytes.Buffer{}, slices.Values([]*Person{people[0], nil}))
+	if !errors.Is(err, ErrNilRecord) || ErrorCode(err) != CodeNilRecord {
+		t.Errorf("expected ErrNilRecord, got %v", err)
+	}
+
+	if _, err := NewCSVAdapter[*string](); !errors.Is(err, ErrorNotStruct) {
+		t.Errorf("expected ErrorNotStruct, got %v", err)
+	}
+}
+
+func TestColumnOrder(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](ColumnOrder("email", "name"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, []Person{{name, age, fakemail}}); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "email,name,age\n" + fakemail + ",John Doe,30\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("name,age,email\nJohn Doe,30," + fakemail + "\n"))
+	if err != nil || len(got) != 1 || got[0] != (Person{name, age, fakemail}) {
+		t.Errorf("expected John Doe, got %+v, %v", got, err)
+	}
+
+	if _, err := NewCSVAdapter[Person](ColumnOrder("email", "phone")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := NewCSVAdapter[Person](ColumnOrder("email", "email")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestSelectOmit(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people := []Person{{name, age, fakemail}}
+
+	selected, err := adapter.Select("email", "name")
+	if err != nil {
+		t.Fatalf("failed to select columns: %v", err)
+	}
+	writer := &bytes.Buffer{}
+	if err := selected.MarshalAll(writer, people); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name,email\nJohn Doe," + fakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+	got, err := selected.UnmarshalAll(strings.NewReader(expected))
+	if err != nil || len(got) != 1 || got[0] != (Person{Name: name, Email: fakemail}) {
+		t.Errorf("expected John Doe without age, got %+v, %v", got, err)
+	}
+
+	omitted, err := adapter.Omit("email")
+	if err != nil {
+		t.Fatalf("failed to omit columns: %v", err)
+	}
+	writer.Reset()
+	if err := omitted.MarshalAll(writer, people); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != "name,age\nJohn Doe,30\n" {
+		t.Errorf("unexpected csv %q", writer.String())
+	}
+
+	// the original adapter is unchanged
+	writer.Reset()
+	if err := adapter.MarshalAll(writer, people); err != nil || writer.String() != "name,age,email\nJohn Doe,30,"+fakemail+"\n" {
+		t.Errorf("expected all columns, got %q, %v", writer.String(), err)
+	}
+
+	if _, err := adapter.Select("password"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestAliasOverrides(t *testing.T) {
+	type Address struct {
+		City string `csva:"city"`
+	}
+	type Customer struct {
+		Name    string  `csva:"name"`
+		Email   string  `csva:"email"`
+		Address Address `csva:"addr,flatten"`
+	}
+	adapter, err := NewCSVAdapter[Customer](AliasOverrides(map[string]string{"Email": "e_mail", "Address.City": "town"}))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,e_mail,town\nJohn Doe," + fakemail + ",Berlin\n"
+	got, err := adapter.UnmarshalAll(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := Customer{name, fakemail, Address{"Berlin"}}
+	if len(got) != 1 || got[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil || writer.String() != data {
+		t.Errorf("expected %q, got %q, %v", data, writer.String(), err)
+	}
+
+	if _, err := NewCSVAdapter[Customer](AliasOverrides(map[string]string{"Phone": "phone"})); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+}
+
+func TestTrimSpace(t *testing.T) {
+	type Row struct {
+		Name  string `csva:"name"`
+		Age   int    `csva:"age"`
+		Email string `csva:"email,omitempty"`
+	}
+	adapter, err := NewCSVAdapter[Row](TrimSpace(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := adapter.UnmarshalAll(strings.NewReader("name,age,email\n John Doe , 30 ,   \n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if rows[0] != (Row{Name: name, Age: age}) {
+		t.Errorf("expected %v, got %v", Row{Name: name, Age: age}, rows[0])
+	}
+
+	type TaggedRow struct {
+		Name string `csva:"name"`
+		Age  int    `csva:"age,trim"`
+	}
+	tagged, err := NewCSVAdapter[TaggedRow]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	taggedRows, err := tagged.UnmarshalAll(strings.NewReader("name,age\n John Doe , 30 \n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if expected := (TaggedRow{Name: " John Doe ", Age: age}); taggedRows[0] != expected {
+		t.Errorf("expected %v, got %v", expected, taggedRows[0])
+	}
+	if _, err := tagged.UnmarshalAll(strings.NewReader("name,age\nJohn Doe,  \n")); !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected %v, got %v", ErrEmptyValue, err)
+	}
+}
+
+func TestAllowMissingColumns(t *testing.T) {
+	type Enriched struct {
+		Name   string `csva:"name,required"`
+		Age    int    `csva:"age"`
+		Region string `csva:"region"`
+	}
+	adapter, err := NewCSVAdapter[Enriched](AllowMissingColumns(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people, err := adapter.UnmarshalAll(strings.NewReader("name,age\nJohn Doe,30\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if expected := []Enriched{{name, age, ""}}; !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+
+	// present columns keep their empty cell semantics
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,region\nJohn Doe,30,\n"))
+	if !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected ErrEmptyValue, got %v", err)
+	}
+
+	// required columns must be present
+	if _, err := adapter.FromCSV(strings.NewReader("age\n30\n")); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+}
+
+func TestAliasWithIndex(t *testing.T) {
+	type LegacyPerson struct {
+		Name  string `csva:"name,index=0"`
+		Email string `csva:"email,index=2,omitempty"`
+		Age   int    `csva:"age,index=1"`
+	}
+	adapter, err := NewCSVAdapter[LegacyPerson]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	expected := []LegacyPerson{{name, fakemail, age}}
+
+	for _, csvData := range []string{
+		"age,email,name\n30," + fakemail + ",John Doe\n",  // bound by header
+		"John Doe,30," + fakemail + "\n",                  // no header, bound by position
+		"name,AGE,e-mail\nJohn Doe,30," + fakemail + "\n", // mangled columns bound by position
+	} {
+		people, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+		if err != nil {
+			t.Fatalf("failed to read %q: %v", csvData, err)
+		}
+		if !slices.Equal(people, expected) {
+			t.Errorf("%q: expected %+v, got %+v", csvData, expected, people)
+		}
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, expected); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if csvData := "name,age,email\nJohn Doe,30," + fakemail + "\n"; writer.String() != csvData {
+		t.Errorf("expected %q, got %q", csvData, writer.String())
+	}
+}
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+	return nil
+}
+
+type Color struct{ R, G, B uint8 }
+
+func (c *Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func TestTextMarshalerReceivers(t *testing.T) {
+	type Task struct {
+		Name  string `csva:"name"`
+		Level Level  `csva:"level"`
+		Color Color  `csva:"color"`
+	}
+	adapter, err := NewCSVAdapter[Task]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	// structs passed by value are not addressable
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, []Task{{"deploy", 1, Color{255, 0, 16}}}); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if expected := "name,level,color\ndeploy,high,#ff0010\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	type LevelOnly struct {
+		Level Level `csva:"level"`
+	}
+	levelAdapter, err := NewCSVAdapter[LevelOnly]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	tasks, err := levelAdapter.UnmarshalAll(strings.NewReader("level\nhigh\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Level != 1 {
+		t.Errorf("expected level 1, got %+v", tasks)
+	}
+}
+
+func TestComplexAndUintptr(t *testing.T) {
+	type Sample struct {
+		Impedance complex128 `csva:"impedance"`
+		Phase     complex64  `csva:"phase"`
+		Address   uintptr    `csva:"address,base=16"`
+	}
+	adapter, err := NewCSVAdapter[Sample]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	samples := []Sample{{complex(1.5, -2.25), complex(0, 1), 0xc000}}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, samples); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if expected := "impedance,phase,address\n(1.5-2.25i),(0+1i),c000\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	read, err := adapter.UnmarshalAll(strings.NewReader(writer.String()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if !slices.Equal(read, samples) {
+		t.Errorf("expected %+v, got %+v", samples, read)
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("impedance,phase,address\n1+i+,0,0\n"))
+	if code := ErrorCode(err); code != CodeParseComplex {
+		t.Errorf("expected %s, got %s (%v)", CodeParseComplex, code, err)
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	type Blob struct {
+		Checksum []byte   `csva:"checksum,enc=hex"`
+		Payload  []byte   `csva:"payload,enc=base64"`
+		Parts    [][]byte `csva:"parts,enc=hex,sep=|"`
+	}
+	adapter, err := NewCSVAdapter[Blob]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	blobs := []Blob{{[]byte{0xde, 0xad, 0xbe, 0xef}, []byte("hello"), [][]byte{{0x01}, {0x02, 0x03}}}}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, blobs); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if expected := "checksum,payload,parts\ndeadbeef,aGVsbG8=,01|0203\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	read, err := adapter.UnmarshalAll(strings.NewReader(writer.String()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(read) != 1 || !bytes.Equal(read[0].Checksum, blobs[0].Checksum) || !bytes.Equal(read[0].Payload, blobs[0].Payload) ||
+		!slices.EqualFunc(read[0].Parts, blobs[0].Parts, bytes.Equal) {
+		t.Errorf("expected %+v, got %+v", blobs, read)
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("checksum,payload,parts\nxyz,aGVsbG8=,01\n"))
+	if !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+
+	type InvalidEncoding struct {
+		Payload []byte `csva:"payload,enc=base32"`
+	}
+	type NotBytes struct {
+		Payload string `csva:"payload,enc=hex"`
+	}
+	for _, err := range []error{second(NewCSVAdapter[InvalidEncoding]()), second(NewCSVAdapter[NotBytes]())} {
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag, got %v", err)
+		}
+	}
+}
+
+func TestEmptySentinel(t *testing.T) {
+	type Score struct {
+		Name  string   `csva:"name"`
+		Score *float64 `csva:"score,empty=N/A|-,omitempty"`
+		Rank  int      `csva:"rank,empty=-,default=99"`
+		Level int      `csva:"level,empty=null"`
+	}
+	adapter, err := NewCSVAdapter[Score]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	scores, err := adapter.UnmarshalAll(strings.NewReader("name,score,rank,level\nJohn Doe,N/A,-,1\nJane Smith,-,3,2\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := []Score{{name, nil, 99, 1}, {othername, nil, 3, 2}}
+	if !slices.Equal(scores, expected) {
+		t.Errorf("expected %+v, got %+v", expected, scores)
+	}
+
+	// sentinels of fields that cannot be empty are rejected like empty cells
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,score,rank,level\nJohn Doe,1,1,null\n"))
+	if !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected ErrEmptyValue, got %v", err)
+	}
+
+	// the first sentinel is written for empty optional fields
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, expected[:1]); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if csvData := "name,score,rank,level\nJohn Doe,N/A,99,1\n"; writer.String() != csvData {
+		t.Errorf("expected %q, got %q", csvData, writer.String())
+	}
+}
+
+func TestPerCallOptions(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	rows, err := adapter.FromCSV(strings.NewReader("name;age;email\nJohn Doe;30;\n"), Comma(';'))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read csv: %v", err)
+		}
+		if row.Name != name || row.Age != 30 {
+			t.Errorf("unexpected row %+v", row)
+		}
+	}
+
+	var output bytes.Buffer
+	if err := adapter.ToCSV(&output, slices.Values([]Person{{name, 30, ""}}), WriteHeader(false)); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if output.String() != "John Doe,30,\n" {
+		t.Errorf("expected no header, got %q", output.String())
+	}
+
+	// the options of the adapter are unchanged
+	output.Reset()
+	if err := adapter.ToCSV(&output, slices.Values([]Person{{name, 30, ""}})); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if output.String() != "name,age,email\nJohn Doe,30,\n" {
+		t.Errorf("expected a header, got %q", output.String())
+	}
+
+	_, err = adapter.FromCSV(strings.NewReader("name,age,email\n"), ColumnOrder("email"))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+}
+
+func TestAllowShortRows(t *testing.T) {
+	type Row struct {
+		Name  string `csva:"name"`
+		Age   int    `csva:"age,default=18"`
+		Email string `csva:"email,omitempty"`
+	}
+	adapter, err := NewCSVAdapter[Row](AllowShortRows(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	rows, err := adapter.FromCSV(strings.NewReader("name,age,email\nJohn Doe,30\nJane Smith\nBob,40,bob@example.com,extra\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var read []Row
+	var errs []error
+	for row, err := range rows {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		read = append(read, row)
+	}
+	expected := []Row{{"John Doe", 30, ""}, {"Jane Smith", 18, ""}}
+	if !slices.Equal(read, expected) {
+		t.Errorf("expected %+v, got %+v", expected, read)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], csv.ErrFieldCount) {
+		t.Errorf("expected a single %v, got %v", csv.ErrFieldCount, errs)
+	}
+
+	strict, err := NewCSVAdapter[Row]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := strict.UnmarshalAll(strings.NewReader("name,age,email\nJohn Doe,30\n")); !errors.Is(err, csv.ErrFieldCount) {
+		t.Errorf("expected %v, got %v", csv.ErrFieldCount, err)
+	}
+}
+
+// closeTracker is a reader recording whether it is closed
+type closeTracker struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeTracker) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestCloseReader(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](CloseReader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	input := "name,age,email\nJohn Doe,30,\nJane Smith,25,\n"
+	reader := &closeTracker{Reader: strings.NewReader(input)}
+	rows, err := adapter.FromCSV(reader)
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	for range rows {
+		break
+	}
+	if reader.closed != 1 {
+		t.Errorf("expected the reader to be closed once when the loop stops, got %d", reader.closed)
+	}
+
+	reader = &closeTracker{Reader: strings.NewReader(input)}
+	decoder, err := adapter.NewDecoder(reader)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if err := decoder.Close(); err != nil || reader.closed != 1 {
+		t.Errorf("expected the reader to be closed, got %v", err)
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("expected %v after Close, got %v", io.EOF, err)
+	}
+	if err := decoder.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+
+	reader = &closeTracker{Reader: strings.NewReader("")}
+	if _, err := adapter.FromCSV(reader); err == nil || reader.closed != 1 {
+		t.Errorf("expected the reader to be closed on error, got %v", err)
+	}
+}
+
+func TestResumeAt(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	input := "name,age,email\nJohn Doe,30,\nJane Smith,25,\nBob,40,\n"
+	decoder, err := adapter.NewDecoder(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	if offset := decoder.InputOffset(); offset != int64(len("name,age,email\n")) {
+		t.Errorf("expected the offset of the first row, got %d", offset)
+	}
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	checkpoint := decoder.InputOffset()
+	if expected := int64(len("name,age,email\nJohn Doe,30,\n")); checkpoint != expected {
+		t.Errorf("expected checkpoint %d, got %d", expected, checkpoint)
+	}
+
+	rows, err := adapter.FromCSV(strings.NewReader(input), ResumeAt(checkpoint))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var names []string
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read csv: %v", err)
+		}
+		names = append(names, row.Name)
+	}
+	if expected := []string{"Jane Smith", "Bob"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	if _, err := adapter.FromCSV(strings.NewReader(input), ResumeAt(3)); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+}
+
+// failingWriter accepts limit bytes, then fails
+type failingWriter struct {
+	limit int
+	n     int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.n+len(p) > f.limit {
+		return 0, io.ErrShortWrite
+	}
+	f.n += len(p)
+	return len(p), nil
+}
+
+func TestFlushEvery(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](FlushEvery(2))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	var output bytes.Buffer
+	encoder, err := adapter.NewEncoder(&output)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	for i, person := range []Person{{name, 30, ""}, {"Jane Smith", 25, ""}, {"Bob", 40, ""}} {
+		if err := encoder.Encode(person); err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		if flushed := output.Len() > 0; flushed != (i >= 1) {
+			t.Errorf("unexpected flush after record %d: %q", i+1, output.String())
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("failed to close encoder: %v", err)
+	}
+
+	people := func(yield func(Person) bool) {
+		for i := 0; ; i++ {
+			if i > 1000 {
+				t.Fatalf("expected writing to stop once the output fails")
+			}
+			if !yield(Person{name, i, ""}) {
+				return
+			}
+		}
+	}
+	if err := adapter.ToCSV(&failingWriter{limit: 100}, people); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected %v, got %v", io.ErrShortWrite, err)
+	}
+}
+
+func TestToCSVWriterErrors(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	// the buffered records fail to be written when flushed
+	err = adapter.ToCSV(&failingWriter{}, slices.Values([]Person{{name, 30, ""}}))
+	if !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected %v, got %v", io.ErrShortWrite, err)
+	}
+
+	// the error of the row is joined with the error of the writer
+	err = adapter.ToCSV(&failingWriter{}, slices.Values([]Person{{name, 30, ""}, {"", 25, ""}}))
+	if !errors.Is(err, ErrEmptyValue) || !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected %v and %v, got %v", ErrEmptyValue, io.ErrShortWrite, err)
+	}
+}
+
+// Test data
+const (
+	fakemail      = "fakemail@mail.com"
+	otherfakemail = "otherfakenail@mail.com"
+	name          = "John Doe"
+	othername     = "Jane Smith"
+	age           = 30
+	otherage      = 25
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// namedReader is a reader that knows its name, like *os.File
+type namedReader struct {
+	io.Reader
+	name string
+}
+
+func (n namedReader) Name() string {
+	return n.name
+}
+
+// second returns the second of two values, e.g. the error of a constructor
+func second[A, B any](_ A, b B) B {
+	return b
 }
@@ -0,0 +1,128 @@
+package csvadapter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// benchmarkRow is a row with fields of the common types
+type benchmarkRow struct {
+	ID     int     `csva:"id"`
+	Name   string  `csva:"name"`
+	Email  string  `csva:"email"`
+	Score  float64 `csva:"score"`
+	Active bool    `csva:"active"`
+}
+
+// benchmarkRows returns n rows and their csv file
+func benchmarkRows(b *testing.B, n int) ([]benchmarkRow, []byte) {
+	b.Helper()
+	rows := make([]benchmarkRow, n)
+	for i := range rows {
+		rows[i] = benchmarkRow{i, fmt.Sprintf("person%d", i), fakemail, float64(i) / 3, i%2 == 0}
+	}
+	adapter, err := NewCSVAdapter[benchmarkRow]()
+	if err != nil {
+		b.Fatalf("failed to create csva: %v", err)
+	}
+	data := &bytes.Buffer{}
+	if err := adapter.MarshalAll(data, rows); err != nil {
+		b.Fatalf("failed to write csv: %v", err)
+	}
+	return rows, data.Bytes()
+}
+
+func BenchmarkFromCSV(b *testing.B) {
+	_, data := benchmarkRows(b, 1000)
+	for _, options := range []struct {
+		name    string
+		options []csvAdapterOption
+	}{
+		{"sequential", nil},
+		{"parallel", []csvAdapterOption{Parallel(4), ReuseRecord(true)}},
+	} {
+		b.Run(options.name, func(b *testing.B) {
+			adapter, err := NewCSVAdapter[benchmarkRow](options.options...)
+			if err != nil {
+				b.Fatalf("failed to create csva: %v", err)
+			}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for range b.N {
+				rows, err := adapter.FromCSV(bytes.NewReader(data))
+				if err != nil {
+					b.Fatalf("failed to read csv: %v", err)
+				}
+				for _, err := range rows {
+					if err != nil {
+						b.Fatalf("failed to read row: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeInto(b *testing.B) {
+	_, data := benchmarkRows(b, 1000)
+	adapter, err := NewCSVAdapter[benchmarkRow](ReuseRecord(true))
+	if err != nil {
+		b.Fatalf("failed to create csva: %v", err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	var row benchmarkRow
+	for range b.N {
+		decoder, err := adapter.NewDecoder(bytes.NewReader(data))
+		if err != nil {
+			b.Fatalf("failed to create decoder: %v", err)
+		}
+		for {
+			if err := decoder.DecodeInto(&row); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatalf("failed to read row: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkToCSV(b *testing.B) {
+	rows, data := benchmarkRows(b, 1000)
+	adapter, err := NewCSVAdapter[benchmarkRow]()
+	if err != nil {
+		b.Fatalf("failed to create csva: %v", err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		if err := adapter.ToCSV(io.Discard, slices.Values(rows)); err != nil {
+			b.Fatalf("failed to write csv: %v", err)
+		}
+	}
+}
+
+// BenchmarkToCSVConcurrent writes many small files concurrently, e.g. in the
+// handlers of a web service, where the pooled scratch state matters most
+func BenchmarkToCSVConcurrent(b *testing.B) {
+	rows, _ := benchmarkRows(b, 10)
+	adapter, err := NewCSVAdapter[benchmarkRow]()
+	if err != nil {
+		b.Fatalf("failed to create csva: %v", err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var writer strings.Builder
+		for pb.Next() {
+			writer.Reset()
+			if err := adapter.MarshalAll(&writer, rows); err != nil {
+				b.Errorf("failed to write csv: %v", err)
+				return
+			}
+		}
+	})
+}
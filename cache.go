@@ -0,0 +1,179 @@
+package csvadapter
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// adapterKey identifies the adapters of GetAdapter
+type adapterKey struct {
+	typ     reflect.Type
+	options string // fingerprint of the options
+}
+
+// maxCachedAdapters is the number of adapters kept by GetAdapter
+//
+// options like Limit or Offset take a new value per read, so without a bound
+// the cache would grow with every value.
+const maxCachedAdapters = 256
+
+// adapterCache keeps the adapters used most recently
+type adapterCache struct {
+	mu      sync.Mutex
+	entries map[adapterKey]*list.Element
+	order   list.List // adapterEntry values, the most recently used first
+}
+
+type adapterEntry struct {
+	key     adapterKey
+	adapter any
+}
+
+// adapters caches the adapters of GetAdapter
+var adapters = &adapterCache{entries: make(map[adapterKey]*list.Element)}
+
+// load returns the adapter of key and marks it as used
+func (a *adapterCache) load(key adapterKey) (any, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	element, ok := a.entries[key]
+	if !ok {
+		return nil, false
+	}
+	a.order.MoveToFront(element)
+	return element.Value.(adapterEntry).adapter, true
+}
+
+// loadOrStore returns the adapter of key, storing adapter if there is none,
+// and evicts the adapter used least recently when the cache is full
+func (a *adapterCache) loadOrStore(key adapterKey, adapter any) any {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if element, ok := a.entries[key]; ok {
+		a.order.MoveToFront(element)
+		return element.Value.(adapterEntry).adapter
+	}
+	a.entries[key] = a.order.PushFront(adapterEntry{key: key, adapter: adapter})
+	if a.order.Len() > maxCachedAdapters {
+		oldest := a.order.Back()
+		a.order.Remove(oldest)
+		delete(a.entries, oldest.Value.(adapterEntry).key)
+	}
+	return adapter
+}
+
+// clear removes all the adapters
+func (a *adapterCache) clear() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	clear(a.entries)
+	a.order.Init()
+}
+
+// GetAdapter returns an adapter for T, like NewCSVAdapter, reusing the adapter
+// created for the same type and options if there is one
+//
+// it is safe for concurrent use, so adapters can be obtained in hot paths
+// (e.g. per request) without parsing the struct every time. Options taking
+// functions (e.g. Filter, Converter, OnRowError) cannot be compared, so with
+// them a new adapter is created every time. Registering a converter clears the cache.
+//
+// the options taking a value per read (e.g. Limit, Offset, ResumeAt) are part
+// of the key too; the cache keeps the 256 adapters used most recently, so
+// passing them here does not grow it forever, but such adapters are better
+// created with NewCSVAdapter.
+func GetAdapter[T any](options ...csvAdapterOption) (*CSVAdapter[T], error) {
+	o := newCSVAdapterOptions()
+	for _, option := range options {
+		option(o)
+	}
+	fingerprint, ok := o.fingerprint()
+	if !ok {
+		return NewCSVAdapter[T](options...)
+	}
+	key := adapterKey{typ: reflect.TypeFor[T](), options: fingerprint}
+	if adapter, ok := adapters.load(key); ok {
+		return adapter.(*CSVAdapter[T]), nil
+	}
+	adapter, err := NewCSVAdapter[T](options...)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.loadOrStore(key, adapter).(*CSVAdapter[T]), nil
+}
+
+// fingerprint describes the options, it reports false if they hold functions
+func (c *csvAdapterOptions) fingerprint() (string, bool) {
+	var b strings.Builder
+	v := reflect.ValueOf(c).Elem()
+	for i := range v.NumField() {
+		name := v.Type().Field(i).Name
+		b.WriteString(name)
+		b.WriteByte('=')
+		if name == "location" {
+			// locations loaded separately are equal, the pointers are not
+			if c.location != nil {
+				b.WriteString(c.location.String())
+			}
+		} else if !writeFingerprint(&b, v.Field(i)) {
+			return "", false
+		}
+		b.WriteByte(';')
+	}
+	return b.String(), true
+}
+
+// writeFingerprint describes the value v, it reports false if v holds functions
+func writeFingerprint(b *strings.Builder, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func:
+		b.WriteString("nil")
+		return v.IsNil()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		return writeFingerprint(b, v.Elem())
+	case reflect.Struct:
+		b.WriteByte('{')
+		for i := range v.NumField() {
+			if !writeFingerprint(b, v.Field(i)) {
+				return false
+			}
+			b.WriteByte(',')
+		}
+		b.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := range v.Len() {
+			if !writeFingerprint(b, v.Index(i)) {
+				return false
+			}
+			b.WriteByte(',')
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		b.WriteByte('{')
+		for _, key := range keys {
+			if !writeFingerprint(b, key) || !writeFingerprint(b, v.MapIndex(key)) {
+				return false
+			}
+			b.WriteByte(',')
+		}
+		b.WriteByte('}')
+	case reflect.String:
+		fmt.Fprintf(b, "%q", v.String())
+	default:
+		fmt.Fprint(b, v)
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package csvadapter
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGetAdapter(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]*CSVAdapter[Person], 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			adapter, err := GetAdapter[Person](Comma(';'), ColumnOrder("email", "name", "age"))
+			if err != nil {
+				t.Errorf("failed to get csva: %v", err)
+			}
+			results[i] = adapter
+		}()
+	}
+	wg.Wait()
+	for _, adapter := range results {
+		if adapter != results[0] {
+			t.Fatalf("expected the same adapter for the same options")
+		}
+	}
+
+	other, err := GetAdapter[Person](Comma(','))
+	if err != nil {
+		t.Fatalf("failed to get csva: %v", err)
+	}
+	if other == results[0] {
+		t.Errorf("expected another adapter for other options")
+	}
+
+	keep := func(p Person) bool { return strings.HasPrefix(p.Name, "J") }
+	filtered, err := GetAdapter[Person](Filter(keep))
+	if err != nil {
+		t.Fatalf("failed to get csva: %v", err)
+	}
+	again, err := GetAdapter[Person](Filter(keep))
+	if err != nil {
+		t.Fatalf("failed to get csva: %v", err)
+	}
+	if filtered == again {
+		t.Errorf("expected adapters with functions not to be cached")
+	}
+}
+
+func TestGetAdapterBounded(t *testing.T) {
+	first, err := GetAdapter[Person](Limit(0))
+	if err != nil {
+		t.Fatalf("failed to get csva: %v", err)
+	}
+	for i := 1; i <= maxCachedAdapters; i++ {
+		if _, err := GetAdapter[Person](Limit(i)); err != nil {
+			t.Fatalf("failed to get csva: %v", err)
+		}
+	}
+	if n := adapters.order.Len(); n > maxCachedAdapters {
+		t.Fatalf("expected at most %d cached adapters, got %d", maxCachedAdapters, n)
+	}
+	again, err := GetAdapter[Person](Limit(0))
+	if err != nil {
+		t.Fatalf("failed to get csva: %v", err)
+	}
+	if again == first {
+		t.Errorf("expected the adapter used least recently to be evicted")
+	}
+}
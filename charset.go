@@ -0,0 +1,126 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// charmap is a single-byte character set
+type charmap struct {
+	name   string
+	decode [256]rune
+	encode map[rune]byte
+}
+
+// newCharmap creates a charmap identical to Latin-1 except for the given runes
+func newCharmap(name string, overrides map[byte]rune) *charmap {
+	m := &charmap{name: name, encode: make(map[rune]byte, 256)}
+	for i := range m.decode {
+		m.decode[i] = rune(i)
+	}
+	for b, r := range overrides {
+		m.decode[b] = r
+	}
+	for i, r := range m.decode {
+		if r != utf8.RuneError {
+			m.encode[r] = byte(i)
+		}
+	}
+	return m
+}
+
+var (
+	latin1 = newCharmap("ISO-8859-1", nil)
+
+	// windows1252 replaces the C1 control characters of Latin-1 with printable characters
+	windows1252 = newCharmap("Windows-1252", map[byte]rune{
+		0x80: '€', 0x81: utf8.RuneError, 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+		0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8D: utf8.RuneError, 0x8E: 'Ž', 0x8F: utf8.RuneError,
+		0x90: utf8.RuneError, 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+		0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9D: utf8.RuneError, 0x9E: 'ž', 0x9F: 'Ÿ',
+	})
+)
+
+// charmapReader decodes a single-byte character set into UTF-8
+type charmapReader struct {
+	r       io.Reader
+	m       *charmap
+	scratch []byte
+	pending []byte
+}
+
+func (c *charmapReader) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 {
+		if cap(c.scratch) < len(p) {
+			c.scratch = make([]byte, len(p))
+		}
+		n, err := c.r.Read(c.scratch[:max(len(p)/utf8.UTFMax, 1)])
+		for _, b := range c.scratch[:n] {
+			c.pending = utf8.AppendRune(c.pending, c.m.decode[b])
+		}
+		if len(c.pending) == 0 {
+			return 0, err
+		}
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[:copy(c.pending, c.pending[n:])]
+	return n, nil
+}
+
+// charmapWriter encodes UTF-8 into a single-byte character set
+type charmapWriter struct {
+	w       io.Writer
+	m       *charmap
+	partial []byte // incomplete rune at the end of the last write
+	scratch []byte
+}
+
+func (c *charmapWriter) Write(p []byte) (int, error) {
+	data := append(c.partial, p...)
+	c.scratch = c.scratch[:0]
+	for len(data) > 0 {
+		if !utf8.FullRune(data) {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		b, ok := c.m.encode[r]
+		if !ok || (r == utf8.RuneError && size == 1) {
+			return 0, errors.Join(ErrCharset, fmt.Errorf("%q is not representable in %s", r, c.m.name))
+		}
+		c.scratch = append(c.scratch, b)
+		data = data[size:]
+	}
+	c.partial = append(c.partial[:0], data...)
+	if _, err := c.w.Write(c.scratch); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close reports an incomplete rune at the end of the data
+func (c *charmapWriter) Close() error {
+	if len(c.partial) > 0 {
+		return errors.Join(ErrCharset, fmt.Errorf("incomplete UTF-8 sequence %q", c.partial))
+	}
+	return nil
+}
+
+// charmapOption transcodes from and to a single-byte character set
+func charmapOption(m *charmap) csvAdapterOption {
+	return Charset(
+		func(r io.Reader) io.Reader { return &charmapReader{r: r, m: m} },
+		func(w io.Writer) io.Writer { return &charmapWriter{w: w, m: m} },
+	)
+}
+
+// Latin1 reads and writes ISO-8859-1 encoded files
+func Latin1() csvAdapterOption {
+	return charmapOption(latin1)
+}
+
+// Windows1252 reads and writes Windows-1252 encoded files
+func Windows1252() csvAdapterOption {
+	return charmapOption(windows1252)
+}
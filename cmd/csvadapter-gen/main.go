@@ -0,0 +1,357 @@
+// Command csvadapter-gen generates methods parsing and formatting the fields of
+// structs without reflection, so csvadapter skips its reflection-based conversions
+//
+// it implements csvadapter.FieldUnmarshaler and csvadapter.FieldMarshaler for the
+// given types of the package in the current directory:
+//
+//	//go:generate go run github.com/ic-it/csvadapter/cmd/csvadapter-gen -type Person,Order
+//
+// fields of basic types (strings, booleans, integers and floats) are generated,
+// unless their tag options change how they are parsed (e.g. conv, json, sep or base);
+// the adapter falls back to reflection for the other fields. If all the fields
+// of a struct are generated, it also implements csvadapter.RecordUnmarshaler and
+// csvadapter.RecordMarshaler, so its rows are read and written without reflection.
+//
+// with -from, it instead declares a struct of the given type with the columns
+// of a sample csv file, their types inferred by csvadapter.InferSchema:
+//
+//	go run github.com/ic-it/csvadapter/cmd/csvadapter-gen -type Person -from people.csv
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ic-it/csvadapter"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <type>_csva.go, or <type>.go with -from")
+	from := flag.String("from", "", "sample csv file to declare the struct type from")
+	comma := flag.String("comma", ",", "field separator of the sample csv file")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	if *from != "" {
+		if len(types) != 1 || len([]rune(*comma)) != 1 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		pkgName := os.Getenv("GOPACKAGE") // set by go generate
+		if pkgName == "" {
+			pkgName = "main"
+		}
+		src, err := generateStruct(*from, types[0], pkgName, []rune(*comma)[0])
+		if err != nil {
+			log.Fatalf("csvadapter-gen: %v", err)
+		}
+		name := *output
+		if name == "" {
+			name = strings.ToLower(types[0]) + ".go"
+		}
+		if err := os.WriteFile(filepath.Clean(name), src, 0o644); err != nil {
+			log.Fatalf("csvadapter-gen: %v", err)
+		}
+		return
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatalf("csvadapter-gen: %v", err)
+	}
+	if len(pkgs) != 1 {
+		log.Fatalf("csvadapter-gen: expected one package, found %d", len(pkgs))
+	}
+	for pkgName, pkg := range pkgs {
+		files := make([]*ast.File, 0, len(pkg.Files))
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+		src, err := generate(pkgName, files, types)
+		if err != nil {
+			log.Fatalf("csvadapter-gen: %v", err)
+		}
+		name := *output
+		if name == "" {
+			name = strings.ToLower(types[0]) + "_csva.go"
+		}
+		if err := os.WriteFile(filepath.Clean(name), src, 0o644); err != nil {
+			log.Fatalf("csvadapter-gen: %v", err)
+		}
+	}
+}
+
+// generateStruct returns the source of a struct typeName with the columns of
+// the csv file at path
+func generateStruct(path, typeName, pkgName string, comma rune) ([]byte, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	schema, err := csvadapter.InferSchema(file, 0, csvadapter.Comma(comma))
+	if err != nil {
+		return nil, err
+	}
+	return schema.GoStruct(pkgName, typeName)
+}
+
+// genField is a field of a struct with generated conversions
+type genField struct {
+	index int    // index of the field in the struct
+	name  string // name of the field
+	kind  string // basic type of the field, e.g. int32
+}
+
+// parseOptions are the tag options changing how a field is parsed or formatted
+var parseOptions = []string{"conv", "json", "sep", "base", "thousands", "decimal", "true", "false", "flatten", "rest"}
+
+// bitSizes of the basic types with generated conversions
+var bitSizes = map[string]int{
+	"string": 0, "bool": 0,
+	"int": 0, "int8": 8, "int16": 16, "int32": 32, "int64": 64,
+	"uint": 0, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64,
+	"float32": 32, "float64": 64,
+}
+
+// generate returns the source of the methods of the types declared in files
+func generate(pkgName string, files []*ast.File, types []string) ([]byte, error) {
+	structs := map[string]*ast.StructType{}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok && slices.Contains(types, spec.Name.Name) {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	fields := make([][]genField, len(types))
+	counts := make([]int, len(types)) // number of fields of each struct, -1 if not all are generated
+	needsStrconv := false
+	for i, typeName := range types {
+		st, ok := structs[typeName]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found", typeName)
+		}
+		fields[i], counts[i] = structFields(st)
+		needsStrconv = needsStrconv || slices.ContainsFunc(fields[i], func(f genField) bool { return f.kind != "string" })
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by csvadapter-gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	if needsStrconv {
+		fmt.Fprintf(&buf, "\t\"strconv\"\n\n")
+	}
+	fmt.Fprintf(&buf, "\t\"github.com/ic-it/csvadapter\"\n)\n")
+	for i, typeName := range types {
+		writeMethods(&buf, typeName, fields[i])
+		if counts[i] >= 0 {
+			writeRecordMethods(&buf, typeName, fields[i], counts[i])
+		}
+	}
+	return format.Source(buf.Bytes())
+}
+
+// structFields returns the fields of st with generated conversions, and the
+// number of fields of st if the adapter reads and writes no other field, -1 otherwise
+func structFields(st *ast.StructType) ([]genField, int) {
+	var fields []genField
+	index := 0
+	complete := true
+	for _, astField := range st.Fields.List {
+		names := astField.Names
+		if len(names) == 0 { // embedded field
+			index++
+			complete = false
+			continue
+		}
+		for _, name := range names {
+			field := genField{index: index, name: name.Name}
+			index++
+			if astField.Tag != nil && isIgnored(astField.Tag.Value) {
+				continue
+			}
+			ident, ok := astField.Type.(*ast.Ident)
+			if !ok || !name.IsExported() {
+				complete = false
+				continue
+			}
+			if _, ok := bitSizes[ident.Name]; !ok {
+				complete = false
+				continue
+			}
+			field.kind = ident.Name
+			if astField.Tag != nil && !hasDefaultFormat(astField.Tag.Value) {
+				complete = false
+				continue
+			}
+			fields = append(fields, field)
+		}
+	}
+	if !complete {
+		return fields, -1
+	}
+	return fields, index
+}
+
+// isIgnored reports whether the csva tag of a field excludes it from the columns
+func isIgnored(literal string) bool {
+	tag, err := strconv.Unquote(literal)
+	if err != nil {
+		return false
+	}
+	name, _, _ := strings.Cut(reflect.StructTag(tag).Get("csva"), ",")
+	return name == "-"
+}
+
+// hasDefaultFormat reports whether the csva tag of a field keeps the default conversions
+func hasDefaultFormat(literal string) bool {
+	tag, err := strconv.Unquote(literal)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(reflect.StructTag(tag).Get("csva"), ",")
+	if parts[0] == "-" {
+		return false
+	}
+	for _, part := range parts[1:] {
+		key, _, _ := strings.Cut(part, "=")
+		if slices.Contains(parseOptions, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeMethods writes the UnmarshalCSVField and MarshalCSVField methods of a type
+func writeMethods(buf *bytes.Buffer, typeName string, fields []genField) {
+	fmt.Fprintf(buf, "\nvar (\n\t_ csvadapter.FieldUnmarshaler = (*%[1]s)(nil)\n\t_ csvadapter.FieldMarshaler = %[1]s{}\n)\n", typeName)
+
+	fmt.Fprintf(buf, "\n// UnmarshalCSVField parses the fields of %s without reflection\n", typeName)
+	fmt.Fprintf(buf, "func (x *%s) UnmarshalCSVField(index int, value string) (bool, error) {\n\tswitch index {\n", typeName)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\tcase %d:\n", f.index)
+		bits := bitSizes[f.kind]
+		switch {
+		case f.kind == "string":
+			fmt.Fprintf(buf, "\t\tx.%s = value\n\t\treturn true, nil\n", f.name)
+			continue
+		case f.kind == "bool":
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseBool(value)\n")
+		case strings.HasPrefix(f.kind, "int"):
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseInt(value, 10, %d)\n", bits)
+		case strings.HasPrefix(f.kind, "uint"):
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseUint(value, 10, %d)\n", bits)
+		default:
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseFloat(value, %d)\n", bits)
+		}
+		fmt.Fprintf(buf, "\t\tif err != nil {\n\t\t\treturn true, err\n\t\t}\n")
+		if f.kind == "bool" {
+			fmt.Fprintf(buf, "\t\tx.%s = v\n", f.name)
+		} else {
+			fmt.Fprintf(buf, "\t\tx.%s = %s(v)\n", f.name, f.kind)
+		}
+		fmt.Fprintf(buf, "\t\treturn true, nil\n")
+	}
+	fmt.Fprintf(buf, "\t}\n\treturn false, nil\n}\n")
+
+	fmt.Fprintf(buf, "\n// MarshalCSVField formats the fields of %s without reflection\n", typeName)
+	fmt.Fprintf(buf, "func (x %s) MarshalCSVField(index int) (string, bool, error) {\n\tswitch index {\n", typeName)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\tcase %d:\n", f.index)
+		switch {
+		case f.kind == "string":
+			fmt.Fprintf(buf, "\t\treturn x.%s, true, nil\n", f.name)
+		case f.kind == "bool":
+			fmt.Fprintf(buf, "\t\treturn strconv.FormatBool(x.%s), true, nil\n", f.name)
+		case strings.HasPrefix(f.kind, "int"):
+			fmt.Fprintf(buf, "\t\treturn strconv.FormatInt(int64(x.%s), 10), true, nil\n", f.name)
+		case strings.HasPrefix(f.kind, "uint"):
+			fmt.Fprintf(buf, "\t\treturn strconv.FormatUint(uint64(x.%s), 10), true, nil\n", f.name)
+		default:
+			fmt.Fprintf(buf, "\t\treturn strconv.FormatFloat(float64(x.%s), 'f', 6, 64), true, nil\n", f.name)
+		}
+	}
+	fmt.Fprintf(buf, "\t}\n\treturn \"\", false, nil\n}\n")
+}
+
+// writeRecordMethods writes the UnmarshalCSVRecord and MarshalCSVRecord methods
+// of a type with count fields, all of them generated
+func writeRecordMethods(buf *bytes.Buffer, typeName string, fields []genField, count int) {
+	fmt.Fprintf(buf, "\nvar (\n\t_ csvadapter.RecordUnmarshaler = (*%[1]s)(nil)\n\t_ csvadapter.RecordMarshaler = %[1]s{}\n)\n", typeName)
+
+	// empty cells and parsing errors are left to the adapter, reporting them
+	fmt.Fprintf(buf, "\n// UnmarshalCSVRecord parses the records of %s without reflection\n", typeName)
+	fmt.Fprintf(buf, "func (x *%s) UnmarshalCSVRecord(record []string, columns []int) bool {\n", typeName)
+	fmt.Fprintf(buf, "\tif len(columns) != %d {\n\t\treturn false\n\t}\n", count)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\tif c := columns[%d]; c >= 0 {\n", f.index)
+		fmt.Fprintf(buf, "\t\tif c >= len(record) || record[c] == \"\" {\n\t\t\treturn false\n\t\t}\n")
+		bits := bitSizes[f.kind]
+		switch {
+		case f.kind == "string":
+			fmt.Fprintf(buf, "\t\tx.%s = record[c]\n\t}\n", f.name)
+			continue
+		case f.kind == "bool":
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseBool(record[c])\n")
+		case strings.HasPrefix(f.kind, "int"):
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseInt(record[c], 10, %d)\n", bits)
+		case strings.HasPrefix(f.kind, "uint"):
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseUint(record[c], 10, %d)\n", bits)
+		default:
+			fmt.Fprintf(buf, "\t\tv, err := strconv.ParseFloat(record[c], %d)\n", bits)
+		}
+		fmt.Fprintf(buf, "\t\tif err != nil {\n\t\t\treturn false\n\t\t}\n")
+		if f.kind == "bool" {
+			fmt.Fprintf(buf, "\t\tx.%s = v\n\t}\n", f.name)
+		} else {
+			fmt.Fprintf(buf, "\t\tx.%s = %s(v)\n\t}\n", f.name, f.kind)
+		}
+	}
+	fmt.Fprintf(buf, "\treturn true\n}\n")
+
+	fmt.Fprintf(buf, "\n// MarshalCSVRecord formats the records of %s without reflection\n", typeName)
+	fmt.Fprintf(buf, "func (x %s) MarshalCSVRecord(record []string, columns []int) bool {\n", typeName)
+	fmt.Fprintf(buf, "\tif len(columns) != %d {\n\t\treturn false\n\t}\n", count)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\tif c := columns[%d]; c >= 0 {\n", f.index)
+		switch {
+		case f.kind == "string":
+			fmt.Fprintf(buf, "\t\tif x.%s == \"\" {\n\t\t\treturn false\n\t\t}\n", f.name)
+			fmt.Fprintf(buf, "\t\trecord[c] = x.%s\n", f.name)
+		case f.kind == "bool":
+			fmt.Fprintf(buf, "\t\trecord[c] = strconv.FormatBool(x.%s)\n", f.name)
+		case strings.HasPrefix(f.kind, "int"):
+			fmt.Fprintf(buf, "\t\trecord[c] = strconv.FormatInt(int64(x.%s), 10)\n", f.name)
+		case strings.HasPrefix(f.kind, "uint"):
+			fmt.Fprintf(buf, "\t\trecord[c] = strconv.FormatUint(uint64(x.%s), 10)\n", f.name)
+		default:
+			fmt.Fprintf(buf, "\t\trecord[c] = strconv.FormatFloat(float64(x.%s), 'f', 6, 64)\n", f.name)
+		}
+		fmt.Fprintf(buf, "\t}\n")
+	}
+	fmt.Fprintf(buf, "\treturn true\n}\n")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const source = `package people
+
+type Person struct {
+	Name     string  ` + "`csva:\"name\"`" + `
+	Age      int     ` + "`csva:\"age,omitempty\"`" + `
+	Flags    uint8   ` + "`csva:\"flags,base=16\"`" + `
+	Score    float32
+	Active   bool    ` + "`csva:\"active\"`" + `
+	Nickname *string ` + "`csva:\"nickname\"`" + `
+	Ignored  int     ` + "`csva:\"-\"`" + `
+	X, Y     int64
+	internal string
+}
+`
+
+func TestGenerate(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "people.go", source, 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	src, err := generate("people", []*ast.File{file}, []string{"Person"})
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	generated := string(src)
+
+	for _, expected := range []string{
+		"package people",
+		"func (x *Person) UnmarshalCSVField(index int, value string) (bool, error) {",
+		"func (x Person) MarshalCSVField(index int) (string, bool, error) {",
+		"x.Name = value",
+		"v, err := strconv.ParseInt(value, 10, 0)",
+		"x.Score = float32(v)",
+		"strconv.FormatFloat(float64(x.Score), 'f', 6, 64)",
+		"strconv.ParseBool(value)",
+		"case 7:",
+		"case 8:",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in:\n%s", expected, generated)
+		}
+	}
+	for _, unexpected := range []string{"x.Flags", "x.Nickname", "x.Ignored", "x.internal"} {
+		if strings.Contains(generated, unexpected) {
+			t.Errorf("unexpected %q in:\n%s", unexpected, generated)
+		}
+	}
+
+	// Flags and Nickname are not generated, so Person has no record methods
+	if strings.Contains(generated, "UnmarshalCSVRecord") {
+		t.Errorf("unexpected record methods in:\n%s", generated)
+	}
+
+	if _, err := generate("people", []*ast.File{file}, []string{"Order"}); err == nil {
+		t.Errorf("expected error for a missing type")
+	}
+}
+
+const recordSource = `package people
+
+type Order struct {
+	ID      string  ` + "`csva:\"id,required\"`" + `
+	Total   float64 ` + "`csva:\"total\"`" + `
+	Offset  int64   ` + "`csva:\"-,byte_offset\"`" + `
+	Paid    bool
+	comment string  ` + "`csva:\"-\"`" + `
+}
+`
+
+func TestGenerateRecords(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "orders.go", recordSource, 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	src, err := generate("people", []*ast.File{file}, []string{"Order"})
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	generated := string(src)
+	for _, expected := range []string{
+		"func (x *Order) UnmarshalCSVRecord(record []string, columns []int) bool {",
+		"func (x Order) MarshalCSVRecord(record []string, columns []int) bool {",
+		"if len(columns) != 5 {",
+		"x.ID = record[c]",
+		"v, err := strconv.ParseFloat(record[c], 64)",
+		"if c := columns[3]; c >= 0 {",
+		"record[c] = strconv.FormatBool(x.Paid)",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in:\n%s", expected, generated)
+		}
+	}
+	for _, unexpected := range []string{"x.Offset", "x.comment"} {
+		if strings.Contains(generated, unexpected) {
+			t.Errorf("unexpected %q in:\n%s", unexpected, generated)
+		}
+	}
+}
+
+func TestGenerateStruct(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.csv")
+	if err := os.WriteFile(path, []byte("name;age\nAlice;30\nBob;\n"), 0o644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+	src, err := generateStruct(path, "Person", "people", ';')
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	generated := string(src)
+	for _, expected := range []string{
+		"package people",
+		"type Person struct {",
+		"Name string `csva:\"name\"`",
+		"Age  int    `csva:\"age,omitempty\"`",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in:\n%s", expected, generated)
+		}
+	}
+}
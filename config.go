@@ -0,0 +1,293 @@
+package csvadapter
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Config is a declarative description of adapter options, e.g. loaded from
+// a per-tenant configuration file
+//
+// empty and nil fields leave the corresponding option untouched.
+// The dialect is applied first, so the other fields override it.
+type Config struct {
+	Dialect string `json:"dialect,omitempty"` // name of a registered dialect
+
+	// encoding/csv options
+	Comma            string `json:"comma,omitempty"`
+	Comment          string `json:"comment,omitempty"`
+	LazyQuotes       *bool  `json:"lazy_quotes,omitempty"`
+	TrimLeadingSpace *bool  `json:"trim_leading_space,omitempty"`
+	ReuseRecord      *bool  `json:"reuse_record,omitempty"`
+	UseCRLF          *bool  `json:"use_crlf,omitempty"`
+
+	// other options
+	WriteHeader           *bool    `json:"write_header,omitempty"`
+	NoImplicitAlias       *bool    `json:"no_implicit_alias,omitempty"`
+	NoHeader              *bool    `json:"no_header,omitempty"`
+	CaseInsensitiveHeader *bool    `json:"case_insensitive_header,omitempty"`
+	StrictHeader          *bool    `json:"strict_header,omitempty"`
+	AllowMissingColumns   *bool    `json:"allow_missing_columns,omitempty"`
+	DuplicateHeaders      string   `json:"duplicate_headers,omitempty"` // last, first, error or sequential
+	WriteBOM              *bool    `json:"write_bom,omitempty"`
+	Charset               string   `json:"charset,omitempty"`     // utf-8, latin1 or windows-1252
+	Compression           string   `json:"compression,omitempty"` // none, gzip or auto
+	Null                  *string  `json:"null,omitempty"`
+	MaxErrors             *int     `json:"max_errors,omitempty"`
+	SkipInvalidRows       *bool    `json:"skip_invalid_rows,omitempty"`
+	ImplicitAliasStyle    string   `json:"implicit_alias_style,omitempty"` // exact, snake_case or lower
+	Quoting               string   `json:"quoting,omitempty"`              // minimal, all, non_numeric or none
+	TrimSpace             *bool    `json:"trim_space,omitempty"`
+	SkipRows              int      `json:"skip_rows,omitempty"`
+	SkipFooter            int      `json:"skip_footer,omitempty"`
+	Limit                 int      `json:"limit,omitempty"`
+	Offset                int      `json:"offset,omitempty"`
+	Parallel              int      `json:"parallel,omitempty"`
+	ReadBufferSize        int      `json:"read_buffer_size,omitempty"`
+	WriteBufferSize       int      `json:"write_buffer_size,omitempty"`
+	PreserveOrder         *bool    `json:"preserve_order,omitempty"`
+	AtomicWrite           *bool    `json:"atomic_write,omitempty"`
+	Location              string   `json:"location,omitempty"` // IANA name, e.g. Europe/Paris
+	WriteUTC              *bool    `json:"write_utc,omitempty"`
+	DedupeBy              []string `json:"dedupe_by,omitempty"`
+	DedupePolicy          string   `json:"dedupe_policy,omitempty"` // first, last or error
+	UniqueLimit           int      `json:"unique_limit,omitempty"`
+	AllowShortRows        *bool    `json:"allow_short_rows,omitempty"`
+	FlushEvery            int      `json:"flush_every,omitempty"`
+	CollectStats          *bool    `json:"collect_stats,omitempty"`
+	RowHashColumn         string   `json:"row_hash_column,omitempty"`
+}
+
+// duplicateHeaderPolicies maps the names of the policies used in configs
+var duplicateHeaderPolicies = map[string]DuplicateHeaderPolicy{
+	"last":       DuplicateLastWins,
+	"first":      DuplicateFirstWins,
+	"error":      DuplicateError,
+	"sequential": DuplicateSequential,
+}
+
+// compressions maps the names of the compression formats used in configs
+var compressions = map[string]Compression{
+	"none": CompressionNone,
+	"gzip": CompressionGzip,
+	"auto": CompressionAuto,
+}
+
+// aliasStyles maps the names of the implicit alias styles used in configs
+var aliasStyles = map[string]AliasStyle{
+	"exact":      ExactName,
+	"snake_case": SnakeCase,
+	"lower":      LowerCase,
+}
+
+// quotePolicies maps the names of the quote policies used in configs
+var quotePolicies = map[string]QuotePolicy{
+	"minimal":     QuoteMinimal,
+	"all":         QuoteAll,
+	"non_numeric": QuoteNonNumeric,
+	"none":        QuoteNone,
+}
+
+// dedupePolicies maps the names of the dedupe policies used in configs
+var dedupePolicies = map[string]DedupePolicy{
+	"first": DedupeFirstWins,
+	"last":  DedupeLastWins,
+	"error": DedupeError,
+}
+
+// OptionsFromStruct converts a Config into adapter options
+func OptionsFromStruct(cfg Config) ([]csvAdapterOption, error) {
+	options := make([]csvAdapterOption, 0)
+
+	if cfg.Dialect != "" {
+		d, ok := LookupDialect(cfg.Dialect)
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown dialect %s", cfg.Dialect))
+		}
+		options = append(options, WithDialect(d))
+	}
+	if cfg.Comma != "" {
+		r, err := configRune("comma", cfg.Comma)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, Comma(r))
+	}
+	if cfg.Comment != "" {
+		r, err := configRune("comment", cfg.Comment)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, Comment(r))
+	}
+	if cfg.LazyQuotes != nil {
+		options = append(options, LazyQuotes(*cfg.LazyQuotes))
+	}
+	if cfg.TrimLeadingSpace != nil {
+		options = append(options, TrimLeadingSpace(*cfg.TrimLeadingSpace))
+	}
+	if cfg.ReuseRecord != nil {
+		options = append(options, ReuseRecord(*cfg.ReuseRecord))
+	}
+	if cfg.UseCRLF != nil {
+		options = append(options, UseCRLF(*cfg.UseCRLF))
+	}
+	if cfg.WriteHeader != nil {
+		options = append(options, WriteHeader(*cfg.WriteHeader))
+	}
+	if cfg.NoImplicitAlias != nil {
+		options = append(options, NoImplicitAlias(*cfg.NoImplicitAlias))
+	}
+	if cfg.NoHeader != nil {
+		options = append(options, NoHeader(*cfg.NoHeader))
+	}
+	if cfg.CaseInsensitiveHeader != nil {
+		options = append(options, CaseInsensitiveHeader(*cfg.CaseInsensitiveHeader))
+	}
+	if cfg.StrictHeader != nil {
+		options = append(options, StrictHeader(*cfg.StrictHeader))
+	}
+	if cfg.AllowMissingColumns != nil {
+		options = append(options, AllowMissingColumns(*cfg.AllowMissingColumns))
+	}
+	if cfg.AllowShortRows != nil {
+		options = append(options, AllowShortRows(*cfg.AllowShortRows))
+	}
+	if cfg.CollectStats != nil {
+		options = append(options, CollectStats(*cfg.CollectStats))
+	}
+	if cfg.DuplicateHeaders != "" {
+		policy, ok := duplicateHeaderPolicies[cfg.DuplicateHeaders]
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown duplicate headers policy %s", cfg.DuplicateHeaders))
+		}
+		options = append(options, DuplicateHeaders(policy))
+	}
+	if cfg.WriteBOM != nil {
+		options = append(options, WriteBOM(*cfg.WriteBOM))
+	}
+	switch cfg.Charset {
+	case "", "utf-8":
+	case "latin1", "iso-8859-1":
+		options = append(options, Latin1())
+	case "windows-1252":
+		options = append(options, Windows1252())
+	default:
+		return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown charset %s", cfg.Charset))
+	}
+	if cfg.Compression != "" {
+		compression, ok := compressions[cfg.Compression]
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown compression %s", cfg.Compression))
+		}
+		options = append(options, WithCompression(compression))
+	}
+	if cfg.Null != nil {
+		options = append(options, NullString(*cfg.Null))
+	}
+	if cfg.MaxErrors != nil {
+		options = append(options, MaxErrors(*cfg.MaxErrors))
+	}
+	if cfg.SkipInvalidRows != nil {
+		options = append(options, SkipInvalidRows(*cfg.SkipInvalidRows))
+	}
+	if cfg.ImplicitAliasStyle != "" {
+		style, ok := aliasStyles[cfg.ImplicitAliasStyle]
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown implicit alias style %s", cfg.ImplicitAliasStyle))
+		}
+		options = append(options, ImplicitAliasStyle(style))
+	}
+	if cfg.SkipRows != 0 {
+		options = append(options, SkipRows(cfg.SkipRows))
+	}
+	if cfg.SkipFooter != 0 {
+		options = append(options, SkipFooter(cfg.SkipFooter))
+	}
+	if cfg.Limit != 0 {
+		options = append(options, Limit(cfg.Limit))
+	}
+	if cfg.Offset != 0 {
+		options = append(options, Offset(cfg.Offset))
+	}
+	if cfg.ReadBufferSize != 0 {
+		options = append(options, ReadBufferSize(cfg.ReadBufferSize))
+	}
+	if cfg.WriteBufferSize != 0 {
+		options = append(options, WriteBufferSize(cfg.WriteBufferSize))
+	}
+	if cfg.Parallel != 0 {
+		options = append(options, Parallel(cfg.Parallel))
+	}
+	if cfg.PreserveOrder != nil {
+		options = append(options, PreserveOrder(*cfg.PreserveOrder))
+	}
+	if cfg.AtomicWrite != nil {
+		options = append(options, AtomicWrite(*cfg.AtomicWrite))
+	}
+	if cfg.Location != "" {
+		location, err := time.LoadLocation(cfg.Location)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidConfig, err)
+		}
+		options = append(options, Location(location))
+	}
+	if cfg.WriteUTC != nil {
+		options = append(options, WriteUTC(*cfg.WriteUTC))
+	}
+	if cfg.UniqueLimit != 0 {
+		options = append(options, UniqueLimit(cfg.UniqueLimit))
+	}
+	if cfg.FlushEvery != 0 {
+		options = append(options, FlushEvery(cfg.FlushEvery))
+	}
+	if cfg.RowHashColumn != "" {
+		options = append(options, RowHashColumn(cfg.RowHashColumn))
+	}
+	if len(cfg.DedupeBy) > 0 {
+		policy, ok := dedupePolicies[cmp.Or(cfg.DedupePolicy, "first")]
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown dedupe policy %s", cfg.DedupePolicy))
+		}
+		options = append(options, DedupeBy(policy, cfg.DedupeBy...))
+	}
+	if cfg.TrimSpace != nil {
+		options = append(options, TrimSpace(*cfg.TrimSpace))
+	}
+	if cfg.Quoting != "" {
+		policy, ok := quotePolicies[cfg.Quoting]
+		if !ok {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("unknown quote policy %s", cfg.Quoting))
+		}
+		options = append(options, Quoting(policy))
+	}
+
+	return options, nil
+}
+
+// OptionsFromJSON converts a JSON encoded Config into adapter options
+//
+// unknown keys are rejected to catch typos in configuration files.
+func OptionsFromJSON(data []byte) ([]csvAdapterOption, error) {
+	var cfg Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	return OptionsFromStruct(cfg)
+}
+
+// configRune parses a single character option
+func configRune(name, value string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError || size != len(value) {
+		return 0, errors.Join(ErrInvalidConfig, fmt.Errorf("%s must be a single character, got %q", name, value))
+	}
+	return r, nil
+}
@@ -0,0 +1,66 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestOptionsFromJSON(t *testing.T) {
+	options, err := OptionsFromJSON([]byte(`{"dialect": "tsv", "comma": ";", "write_header": false, "null": "NULL"}`))
+	if err != nil {
+		t.Fatalf("failed to load options: %v", err)
+	}
+
+	type PersonWithNullable struct {
+		Name  string  `csva:"name"`
+		Email *string `csva:"email,omitempty"`
+	}
+	adapter, err := NewCSVAdapter[PersonWithNullable](options...)
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	writer := &bytes.Buffer{}
+	people := []PersonWithNullable{{name, stringPtr(fakemail)}, {othername, nil}}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "John Doe;" + fakemail + "\nJane Smith;NULL\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
+
+func TestOptionsFromJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed", `{"comma":`},
+		{"unknown key", `{"delimiter": ";"}`},
+		{"unknown dialect", `{"dialect": "foo"}`},
+		{"long comma", `{"comma": ";;"}`},
+		{"long comment", `{"comment": "##"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OptionsFromJSON([]byte(tt.data))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOptionsFromStruct(t *testing.T) {
+	trueValue := true
+	options, err := OptionsFromStruct(Config{Comment: "#", NoImplicitAlias: &trueValue})
+	if err != nil {
+		t.Fatalf("failed to load options: %v", err)
+	}
+	if _, err := NewCSVAdapter[PersonNoTags](options...); !errors.Is(err, ErrAliasNotFound) {
+		t.Errorf("expected ErrAliasNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package csvadapter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// limit is a min or max constraint of a field
+//
+// numbers are compared to the value, strings and slices by their length.
+type limit struct {
+	text string // value of the tag option
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// parseLimit parses the value of a min or max tag option for a field of type t
+func parseLimit(t reflect.Type, text string) (*limit, error) {
+	l := &limit{text: text}
+	var err error
+	switch indirectType(t).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.String, reflect.Slice:
+		l.i, err = strconv.ParseInt(text, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		l.u, err = strconv.ParseUint(text, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		l.f, err = strconv.ParseFloat(text, 64)
+	default:
+		return nil, fmt.Errorf("type %s has no limits", t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// compare returns -1, 0 or +1 if v is less than, equal to or greater than the limit
+func (l *limit) compare(v reflect.Value) int {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(v.Int(), l.i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(v.Uint(), l.u)
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(v.Float(), l.f)
+	case reflect.String:
+		return cmp.Compare(int64(utf8.RuneCountInString(v.String())), l.i)
+	default:
+		return cmp.Compare(int64(v.Len()), l.i)
+	}
+}
+
+// checkConstraints checks the decoded value v and the raw cell value
+// against the min, max and pattern tag options of the field
+//
+// it returns the violated constraint, e.g. "max=150", and the error.
+func (f *field) checkConstraints(v reflect.Value, value string) (string, error) {
+	if f.pattern != nil && !f.pattern.MatchString(value) {
+		return _TAG_PATTERN + "=" + f.pattern.String(), errors.Join(ErrConstraintViolation, fmt.Errorf("%q does not match %s", value, f.pattern))
+	}
+	if f.min != nil && f.min.compare(v) < 0 {
+		return _TAG_MIN + "=" + f.min.text, errors.Join(ErrConstraintViolation, fmt.Errorf("%q is less than %s", value, f.min.text))
+	}
+	if f.max != nil && f.max.compare(v) > 0 {
+		return _TAG_MAX + "=" + f.max.text, errors.Join(ErrConstraintViolation, fmt.Errorf("%q is greater than %s", value, f.max.text))
+	}
+	return "", nil
+}
@@ -0,0 +1,82 @@
+package csvadapter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	type Product struct {
+		SKU   string  `csva:"sku,pattern=^[A-Z]{3}-\\d+$"`
+		Name  string  `csva:"name,min=2,max=10"`
+		Stock uint    `csva:"stock,max=100"`
+		Price float64 `csva:"price,min=0.01"`
+		Age   *int    `csva:"age,omitempty,min=0,max=150"`
+	}
+	adapter, err := NewCSVAdapter[Product]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		row        string
+		constraint string
+	}{
+		{"valid", "ABC-1,pen,100,0.5,30", ""},
+		{"pattern", "abc-1,pen,1,0.5,30", `pattern=^[A-Z]{3}-\d+$`},
+		{"min length", "ABC-1,p,1,0.5,30", "min=2"},
+		{"max length", "ABC-1,fountain pen,1,0.5,30", "max=10"},
+		{"max uint", "ABC-1,pen,101,0.5,30", "max=100"},
+		{"min float", "ABC-1,pen,1,0,30", "min=0.01"},
+		{"max pointer", "ABC-1,pen,1,0.5,151", "max=150"},
+		{"omitted pointer", "ABC-1,pen,1,0.5,", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := adapter.UnmarshalAll(strings.NewReader("sku,name,stock,price,age\n" + tt.row + "\n"))
+			if tt.constraint == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrConstraintViolation) || ErrorCode(err) != CodeConstraint {
+				t.Fatalf("expected ErrConstraintViolation, got %v", err)
+			}
+			readingErr, _ := AsReadingError(err)
+			if readingErr.Constraint != tt.constraint {
+				t.Errorf("expected constraint %q, got %q", tt.constraint, readingErr.Constraint)
+			}
+		})
+	}
+
+	type WrongLimit struct {
+		Age int `csva:"age,min=zero"`
+	}
+	if _, err := NewCSVAdapter[WrongLimit](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+	type Code struct {
+		Code  string `csva:"code,omitempty,pattern=^[A-Z]{2,3}[,;]\\d{1,2}$"`
+		Other string `csva:"other"`
+	}
+	codes, err := NewCSVAdapter[Code]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := codes.UnmarshalAll(strings.NewReader("code,other\n\"AB,12\",x\n")); err != nil {
+		t.Errorf("expected a valid row, got %v", err)
+	}
+	if _, err := codes.UnmarshalAll(strings.NewReader("code,other\nABCD;1,x\n")); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected ErrConstraintViolation, got %v", err)
+	}
+
+	type WrongPattern struct {
+		SKU string `csva:"sku,pattern=[A-Z"`
+	}
+	if _, err := NewCSVAdapter[WrongPattern](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package csvadapter
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// converter parses and formats the values of a type
+type converter struct {
+	typ    reflect.Type
+	parse  func(value string) (reflect.Value, error)
+	format func(v reflect.Value) (string, error)
+}
+
+// newConverter wraps typed parse and format functions into a converter
+func newConverter[V any](parse func(string) (V, error), format func(V) (string, error)) converter {
+	return converter{
+		typ: reflect.TypeFor[V](),
+		parse: func(value string) (reflect.Value, error) {
+			v, err := parse(value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		},
+		format: func(v reflect.Value) (string, error) {
+			return format(v.Interface().(V))
+		},
+	}
+}
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[reflect.Type]converter{}
+)
+
+// RegisterConverter registers the functions used to parse and format the values of type V
+//
+// converters take precedence over the built-in conversions and encoding.TextMarshaler,
+// so types of other packages (e.g. decimals or enums) can be used without wrappers.
+// Registered converters apply to adapters created afterwards; registering a type twice
+// replaces the previous converter.
+func RegisterConverter[V any](parse func(string) (V, error), format func(V) (string, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	conv := newConverter(parse, format)
+	converters[conv.typ] = conv
+	// the cached adapters were created with the previous converters
+	adapters.clear()
+}
+
+// resolveConverters merges the registered converters with the converters of an adapter
+func resolveConverters(adapterConverters map[reflect.Type]converter) map[reflect.Type]converter {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	resolved := maps.Clone(converters)
+	maps.Copy(resolved, adapterConverters)
+	return resolved
+}
+
+// convertsField reports whether conv applies to a field of type t,
+// directly or to the elements of pointers and slices
+func (conv converter) convertsField(t reflect.Type) bool {
+	for {
+		if t == conv.typ {
+			return true
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type cents int64
+
+func parseCents(value string) (cents, error) {
+	var units, hundredths int64
+	if _, err := fmt.Sscanf(value, "%d.%02d", &units, &hundredths); err != nil {
+		return 0, err
+	}
+	return cents(units*100 + hundredths), nil
+}
+
+func formatCents(c cents) (string, error) {
+	return fmt.Sprintf("%d.%02d", c/100, c%100), nil
+}
+
+type level int
+
+func TestRegisterConverter(t *testing.T) {
+	type Product struct {
+		Name  string  `csva:"name"`
+		Price cents   `csva:"price"`
+		Old   *cents  `csva:"old,omitempty"`
+		Tiers []cents `csva:"tiers,sep=|"`
+	}
+	RegisterConverter(parseCents, formatCents)
+
+	adapter, err := NewCSVAdapter[Product]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	old := cents(1999)
+	products := []Product{{"pen", 1250, &old, []cents{100, 205}}}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(products)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name,price,old,tiers\npen,12.50,19.99,1.00|2.05\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader(expected))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0].Price != 1250 || *got[0].Old != old || !slices.Equal(got[0].Tiers, products[0].Tiers) {
+		t.Errorf("expected %+v, got %+v", products, got)
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,price,tiers\npen,free,1.00\n"))
+	if !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+}
+
+func TestConverterOption(t *testing.T) {
+	type Task struct {
+		Name  string `csva:"name"`
+		Level level  `csva:"level"`
+	}
+	levels := []string{"low", "high"}
+	adapter, err := NewCSVAdapter[Task](Converter(
+		func(value string) (level, error) {
+			i := slices.Index(levels, value)
+			if i < 0 {
+				return 0, fmt.Errorf("unknown level %s", value)
+			}
+			return level(i), nil
+		},
+		func(l level) (string, error) {
+			return levels[l], nil
+		},
+	))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("name,level\nwrite tests,high\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0].Level != 1 {
+		t.Errorf("expected level 1, got %+v", got)
+	}
+
+	// other adapters are not affected
+	plain, err := NewCSVAdapter[Task]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := plain.UnmarshalAll(strings.NewReader("name,level\nwrite tests,high\n")); !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+}
+
+func TestNamedConverter(t *testing.T) {
+	type Item struct {
+		Code string   `csva:"code,conv=upper"`
+		Name string   `csva:"name"`
+		Nick *string  `csva:"nick,conv=upper,omitempty"`
+		Tags []string `csva:"tags,conv=upper,sep=;"`
+	}
+	upper := WithConverter("upper",
+		func(value string) (string, error) { return strings.ToUpper(value), nil },
+		func(value string) (string, error) { return strings.ToUpper(value), nil },
+	)
+	adapter, err := NewCSVAdapter[Item](upper)
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("code,name,nick,tags\nab-1,pen,blue,a;b\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0].Code != "AB-1" || got[0].Name != "pen" || *got[0].Nick != "BLUE" || !slices.Equal(got[0].Tags, []string{"A", "B"}) {
+		t.Errorf("unexpected items %+v", got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, []Item{{Code: "cd-2", Name: "ink", Tags: []string{"c"}}}); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "code,name,nick,tags\nCD-2,ink,,C\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	type Unknown struct {
+		Code string `csva:"code,conv=lower"`
+	}
+	if _, err := NewCSVAdapter[Unknown](upper); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+	type WrongType struct {
+		Count int `csva:"count,conv=upper"`
+	}
+	if _, err := NewCSVAdapter[WrongType](upper); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package csvadaptertest provides helpers to test csvadapter mappings
+// with little boilerplate: golden files, round trips and fuzz corpora.
+package csvadaptertest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ic-it/csvadapter"
+)
+
+// UpdateEnv is the environment variable that makes AssertEncodes
+// (re)write golden files instead of comparing against them
+const UpdateEnv = "CSVADAPTERTEST_UPDATE"
+
+// AssertEncodes checks that rows are encoded into the content of the golden file
+//
+// run the tests with CSVADAPTERTEST_UPDATE=1 to create or update the golden file.
+func AssertEncodes[T any](t testing.TB, adapter *csvadapter.CSVAdapter[T], rows []T, golden string) {
+	t.Helper()
+
+	got, err := encode(adapter, rows)
+	if err != nil {
+		t.Fatalf("failed to encode rows: %v", err)
+		return
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+		return
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("encoded rows do not match %s\nexpected:\n%s\ngot:\n%s", golden, expected, got)
+	}
+}
+
+// AssertDecodes checks that the content of the golden file is decoded into expected
+func AssertDecodes[T any](t testing.TB, adapter *csvadapter.CSVAdapter[T], golden string, expected []T) {
+	t.Helper()
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+		return
+	}
+	got, err := decode(adapter, data)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", golden, err)
+		return
+	}
+	if !equalRows(got, expected) {
+		t.Errorf("decoded rows do not match\nexpected: %+v\ngot:      %+v", expected, got)
+	}
+}
+
+// AssertRoundTrip checks that rows are unchanged after being encoded and decoded again
+//
+// note that floats are written with a fixed precision, so they may not survive a round trip.
+func AssertRoundTrip[T any](t testing.TB, adapter *csvadapter.CSVAdapter[T], rows []T) {
+	t.Helper()
+
+	data, err := encode(adapter, rows)
+	if err != nil {
+		t.Fatalf("failed to encode rows: %v", err)
+		return
+	}
+	got, err := decode(adapter, data)
+	if err != nil {
+		t.Fatalf("failed to decode rows: %v\ncsv:\n%s", err, data)
+		return
+	}
+	if !equalRows(got, rows) {
+		t.Errorf("rows changed after a round trip\nexpected: %+v\ngot:      %+v\ncsv:\n%s", rows, got, data)
+	}
+}
+
+// Corpus generates a fuzz-friendly corpus from the adapter's schema and seed rows
+//
+// besides the encoded seed rows, the corpus contains the header alone, every
+// row alone, and variants with blanked, invalid, missing and extra columns
+// as well as a truncated row. It is meant to be passed to (*testing.F).Add.
+func Corpus[T any](adapter *csvadapter.CSVAdapter[T], rows []T) ([][]byte, error) {
+	data, err := encode(adapter, rows)
+	if err != nil {
+		return nil, err
+	}
+	dialect := adapter.Dialect()
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = dialect.Comma
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var header [][]string
+	body := records
+	if !dialect.NoHeader && len(records) > 0 {
+		header, body = records[:1], records[1:]
+	}
+	width := 0
+	if len(records) > 0 {
+		width = len(records[0])
+	}
+
+	corpus := [][]byte{data}
+	add := func(records [][]string) error {
+		buf := &bytes.Buffer{}
+		writer := csv.NewWriter(buf)
+		writer.Comma = dialect.Comma
+		writer.UseCRLF = dialect.UseCRLF
+		if err := writer.WriteAll(records); err != nil {
+			return err
+		}
+		corpus = append(corpus, buf.Bytes())
+		return nil
+	}
+	// mapColumn returns a copy of the document with every body cell of a column replaced
+	mapColumn := func(column int, mapCell func(cell string) []string) [][]string {
+		out := make([][]string, 0, len(records))
+		for _, record := range header {
+			out = append(out, mapRecord(record, column, func(cell string) []string { return []string{cell} }))
+		}
+		for _, record := range body {
+			out = append(out, mapRecord(record, column, mapCell))
+		}
+		return out
+	}
+
+	if header != nil {
+		if err := add(header); err != nil {
+			return nil, err
+		}
+	}
+	for _, record := range body {
+		if err := add(append(slices.Clone(header), record)); err != nil {
+			return nil, err
+		}
+	}
+	for column := range width {
+		variants := [][][]string{
+			mapColumn(column, func(string) []string { return []string{""} }),
+			mapColumn(column, func(string) []string { return []string{"\x00invalid"} }),
+		}
+		// drop the column everywhere, including the header
+		dropped := make([][]string, 0, len(records))
+		for _, record := range records {
+			dropped = append(dropped, mapRecord(record, column, func(string) []string { return nil }))
+		}
+		variants = append(variants, dropped)
+		for _, variant := range variants {
+			if err := add(variant); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if width > 0 {
+		extra := make([][]string, 0, len(records))
+		for i, record := range records {
+			cell := "extra"
+			if header != nil && i == 0 {
+				cell = "extra_column"
+			}
+			extra = append(extra, append(slices.Clone(record), cell))
+		}
+		if err := add(extra); err != nil {
+			return nil, err
+		}
+	}
+	if len(body) > 0 {
+		last := body[len(body)-1]
+		truncated := append(slices.Clone(records[:len(records)-1]), last[:len(last)-1])
+		if err := add(truncated); err != nil {
+			return nil, err
+		}
+	}
+
+	return corpus, nil
+}
+
+// mapRecord returns a copy of record with the cell at column replaced by the cells returned by mapCell
+func mapRecord(record []string, column int, mapCell func(cell string) []string) []string {
+	out := make([]string, 0, len(record))
+	out = append(out, record[:column]...)
+	out = append(out, mapCell(record[column])...)
+	out = append(out, record[column+1:]...)
+	return out
+}
+
+// equalRows compares rows deeply, treating nil and empty slices as equal
+func equalRows[T any](a, b []T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// encode writes rows with the adapter
+func encode[T any](adapter *csvadapter.CSVAdapter[T], rows []T) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := adapter.ToCSV(buf, slices.Values(rows)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode reads all rows with the adapter, stopping at the first error
+func decode[T any](adapter *csvadapter.CSVAdapter[T], data []byte) ([]T, error) {
+	seq, err := adapter.FromCSV(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]T, 0)
+	for row, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
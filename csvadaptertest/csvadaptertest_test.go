@@ -0,0 +1,146 @@
+package csvadaptertest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ic-it/csvadapter"
+)
+
+type Person struct {
+	Name  string `csva:"name"`
+	Age   int    `csva:"age"`
+	Email string `csva:"email,omitempty"`
+}
+
+var people = []Person{
+	{"John Doe", 30, "fakemail@mail.com"},
+	{"Jane Smith", 25, ""},
+}
+
+// recorder is a testing.TB that records failures instead of failing the test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func newAdapter(t *testing.T) *csvadapter.CSVAdapter[Person] {
+	adapter, err := csvadapter.NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	return adapter
+}
+
+func TestAssertEncodes(t *testing.T) {
+	adapter := newAdapter(t)
+	AssertEncodes(t, adapter, people, "testdata/people.csv")
+
+	r := &recorder{TB: t}
+	AssertEncodes(r, adapter, people[:1], "testdata/people.csv")
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
+
+func TestAssertEncodesUpdate(t *testing.T) {
+	t.Setenv(UpdateEnv, "1")
+	golden := filepath.Join(t.TempDir(), "nested", "people.csv")
+
+	adapter := newAdapter(t)
+	AssertEncodes(t, adapter, people, golden)
+
+	got, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	expected, err := os.ReadFile("testdata/people.csv")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestAssertDecodes(t *testing.T) {
+	adapter := newAdapter(t)
+	AssertDecodes(t, adapter, "testdata/people.csv", people)
+
+	r := &recorder{TB: t}
+	AssertDecodes(r, adapter, "testdata/people.csv", people[:1])
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	adapter := newAdapter(t)
+	AssertRoundTrip(t, adapter, people)
+	AssertRoundTrip(t, adapter, nil)
+
+	type Lossy struct {
+		Value float64 `csva:"value"`
+	}
+	lossy, err := csvadapter.NewCSVAdapter[Lossy]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	r := &recorder{TB: t}
+	AssertRoundTrip(r, lossy, []Lossy{{1.0 / 3}})
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	adapter := newAdapter(t)
+	corpus, err := Corpus(adapter, people)
+	if err != nil {
+		t.Fatalf("failed to generate corpus: %v", err)
+	}
+
+	// full, header, 2 rows, 3 variants for each of 3 columns, extra column, truncated row
+	if len(corpus) != 1+1+2+3*3+1+1 {
+		t.Errorf("unexpected corpus size %d", len(corpus))
+	}
+	expected := "name,age\nJohn Doe,30\nJane Smith,25\n"
+	if !bytes.Contains(bytes.Join(corpus, []byte("|")), []byte(expected)) {
+		t.Errorf("expected corpus to contain %q", expected)
+	}
+}
+
+func FuzzFromCSV(f *testing.F) {
+	adapter, err := csvadapter.NewCSVAdapter[Person]()
+	if err != nil {
+		f.Fatalf("failed to create csva: %v", err)
+	}
+	corpus, err := Corpus(adapter, people)
+	if err != nil {
+		f.Fatalf("failed to generate corpus: %v", err)
+	}
+	for _, data := range corpus {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rows, err := adapter.FromCSV(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		for range rows {
+		}
+	})
+}
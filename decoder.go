@@ -0,0 +1,762 @@
+package csvadapter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Decoder reads structs from a csv file one record at a time
+//
+// it is the pull-based counterpart of FromCSV: the caller owns the loop
+// and decides what to do with every row and error.
+type Decoder[T any] struct {
+	adapter     *CSVAdapter[T]
+	reader      RecordReader
+	header      []string // nil for positional adapters and with the NoHeader option
+	columns     []int    // csv column of each field, -1 if missing
+	restColumns []int    // csv columns not mapped to any field
+	sourceName  string   // name of the source, if the reader knows it (e.g. *os.File)
+	consumed    int64    // bytes consumed before the csv data (e.g. a BOM)
+	line        int      // number of the last record read
+	failures    int      // number of rows that failed
+	skipped     int      // number of invalid rows skipped
+	stopped     bool     // if the decoder stopped after too many errors
+	decoded     int      // number of records decoded successfully
+	discarded   int      // number of records discarded with the Offset option
+	dropped     int      // number of records dropped by the FilterRecord option
+	filtered    int      // number of rows dropped by the Filter option
+
+	// record read ahead by More
+	peeked bool
+	record []string
+	offset int64
+	end    int64
+	err    error
+
+	// keys of the DedupeBy option
+	dedupeFields []int          // fields of the key
+	seen         map[string]int // line of the first row of each key
+	held         []T            // rows held back by DedupeLastWins
+	heldIndex    map[string]int // index of the row of each key in held
+
+	// values of the columns with the unique tag option
+	uniques []uniqueSet
+
+	// columns of the fields of the structs implementing RecordUnmarshaler, nil if not used
+	recordColumns []int
+
+	// records held back until they are known not to be part of the footer,
+	// or the first record in fallback mode if it is not a header
+	pending    []rawRecord
+	inputEnded bool
+
+	width int // number of cells of the header or the first record, with AllowShortRows
+
+	closer io.Closer // reader closed by Close, with the CloseReader option
+	closed bool
+
+	resumeOffset int64 // offset following the last record read
+
+	columnStats []columnStats // metrics of the columns, with the CollectStats option
+}
+
+// rawRecord is a record read from the csv reader
+type rawRecord struct {
+	record []string
+	offset int64 // offset of the record in the input
+	end    int64 // offset following the record in the input
+	err    error
+	line   int // number of the record, set once it is consumed
+}
+
+// NewDecoder creates a Decoder reading from reader
+//
+// the header is read immediately, so header errors are reported here.
+// options apply to this decoder only, on top of the options of the adapter.
+func (c *CSVAdapter[T]) NewDecoder(reader io.Reader, options ...csvAdapterOption) (*Decoder[T], error) {
+	c, err := c.with(options)
+	if err != nil {
+		return nil, err
+	}
+	input, consumed, err := c.options.openReader(reader)
+	if err != nil {
+		return nil, c.closeSource(reader, err)
+	}
+	var buffered *bufio.Reader
+	if c.options.resumeAt > 0 {
+		// encoding/csv reads through the buffered reader as is if it is large enough
+		var ok bool
+		if buffered, ok = input.(*bufio.Reader); !ok || buffered.Size() < defaultBufferSize {
+			buffered = bufio.NewReaderSize(input, defaultBufferSize)
+		}
+		input = buffered
+	}
+	csvReader := csv.NewReader(input)
+	c.options.applyReader(csvReader)
+	decoder, err := c.newDecoder(csvReader, consumed, reader)
+	if err != nil || buffered == nil {
+		return decoder, err
+	}
+	if err := decoder.resume(buffered, c.options.resumeAt); err != nil {
+		return nil, c.closeSource(reader, err)
+	}
+	return decoder, nil
+}
+
+// resume skips the input up to offset, the header being read
+func (d *Decoder[T]) resume(buffered *bufio.Reader, offset int64) error {
+	start := d.consumed + inputOffset(d.reader)
+	if offset < start {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("resume offset %d is before the first row at %d", offset, start))
+	}
+	// the first record read in fallback mode is before the offset
+	d.pending = nil
+	n, err := buffered.Discard(int(offset - start))
+	d.consumed += int64(n)
+	d.resumeOffset = offset
+	if err != nil && err != io.EOF {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	return nil
+}
+
+// closeSource closes source after err with the CloseReader option
+func (c *CSVAdapter[T]) closeSource(source any, err error) error {
+	if closer, ok := source.(io.Closer); ok && c.options.closeReader {
+		return errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// newDecoder creates a Decoder reading the records of reader, after consumed bytes of source
+func (c *CSVAdapter[T]) newDecoder(reader RecordReader, consumed int64, source any) (decoder *Decoder[T], err error) {
+	defer func() {
+		if err != nil {
+			err = c.closeSource(source, err)
+		}
+	}()
+	// positional adapters read csv files without a header
+	var header []string
+	if !c.positional && !c.options.noHeader {
+		header, err = reader.Read()
+		if err != nil {
+			return nil, errors.Join(ErrReadingCSVLines, err)
+		}
+	}
+	width := len(header)
+	// in fallback mode, a first record without any alias is the first row
+	var pending []rawRecord
+	if c.fallback && header != nil && !c.isHeader(header) {
+		pending = append(pending, rawRecord{record: slices.Clone(header), offset: consumed, end: consumed + inputOffset(reader)})
+		header = nil
+	}
+	columns, restColumns, err := c.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder = &Decoder[T]{
+		adapter:     c,
+		reader:      reader,
+		header:      header,
+		columns:     columns,
+		restColumns: restColumns,
+		consumed:    consumed,
+		pending:     pending,
+		width:       width,
+
+		resumeOffset: consumed + inputOffset(reader),
+	}
+	if len(pending) > 0 {
+		decoder.resumeOffset = consumed
+	}
+	if err := decoder.prepare(source); err != nil {
+		return nil, err
+	}
+	if closer, ok := source.(io.Closer); ok && c.options.closeReader {
+		decoder.closer = closer
+	}
+	return decoder, nil
+}
+
+// prepare sets up the state of the decoder for the options checking rows against each other
+func (d *Decoder[T]) prepare(source any) error {
+	c := d.adapter
+	d.uniques = c.newUniqueSets()
+	if reflect.PointerTo(c.structType).Implements(reflect.TypeFor[RecordUnmarshaler]()) {
+		d.recordColumns = c.recordColumns(d.columns)
+	}
+	if c.options.dedupe != nil {
+		var err error
+		if d.dedupeFields, err = c.dedupeFields(); err != nil {
+			return err
+		}
+		d.seen = make(map[string]int)
+		d.heldIndex = make(map[string]int)
+	}
+	if named, ok := source.(interface{ Name() string }); ok {
+		d.sourceName = named.Name()
+	}
+	return nil
+}
+
+// More reports whether there is another record to decode
+//
+// it reads the next record ahead, so malformed rows are reported by the following Decode.
+// It returns false once the Limit option is reached.
+func (d *Decoder[T]) More() bool {
+	options := d.adapter.options
+	if d.stopped || (options.limit > 0 && d.decoded >= options.limit) {
+		return false
+	}
+	return d.peek()
+}
+
+// peek reads the next record ahead, skipping the records of the Offset option
+func (d *Decoder[T]) peek() bool {
+	options := d.adapter.options
+	for !d.peeked {
+		r := d.next()
+		if r.err != io.EOF && d.discarded < options.offset {
+			d.discarded++
+			d.line++
+			continue
+		}
+		d.record, d.offset, d.end, d.err = r.record, r.offset, r.end, r.err
+		d.peeked = true
+	}
+	return d.err != io.EOF
+}
+
+// Decode decodes the next record
+//
+// it returns io.EOF when there are no more records. Errors of a single row
+// do not stop the decoder: the next call continues with the following row,
+// unless the MaxErrors limit is reached. With the OnRowError and
+// SkipInvalidRows options, invalid rows are skipped instead of being returned.
+func (d *Decoder[T]) Decode() (T, error) {
+	var TEmpty T
+	for !d.stopped {
+		item, err := d.decode()
+		if err == io.EOF {
+			if held, ok := d.nextHeld(); ok {
+				return held, nil
+			}
+			return item, err
+		}
+		if item, ok, err := d.settle(item, err, d.line, d.record); ok {
+			return item, err
+		}
+	}
+	return TEmpty, io.EOF
+}
+
+// settle applies the Filter and the error handling options to a decoded row
+//
+// it reports whether the row or its error is returned to the caller.
+func (d *Decoder[T]) settle(item T, err error, line int, record []string) (T, bool, error) {
+	var TEmpty T
+	options := d.adapter.options
+	if err == nil {
+		if options.filter != nil && !options.filter.keep(item) {
+			d.filtered++
+			return TEmpty, false, nil
+		}
+	}
+	if err == nil && d.uniques != nil {
+		err = d.checkUnique(line, record)
+	}
+	if err == nil && options.dedupe != nil {
+		var ok bool
+		if ok, err = d.dedupe(item, line, record); err == nil && !ok {
+			return TEmpty, false, nil
+		}
+	}
+	if err == nil {
+		d.decoded++
+		return item, true, nil
+	}
+	d.failures++
+	tooMany := options.maxErrors > 0 && d.failures >= options.maxErrors
+	if tooMany {
+		d.stopped = true
+		err = errors.Join(err, ErrTooManyErrors)
+	}
+	if options.onRowError == nil && !options.skipInvalidRows {
+		return TEmpty, true, err
+	}
+	d.skipped++
+	if options.onRowError != nil && !options.onRowError(line, slices.Clone(record), err) {
+		d.stopped = true
+	}
+	// the rows left are not read, so the error is returned even if the row is skipped
+	if tooMany {
+		return TEmpty, true, err
+	}
+	return TEmpty, false, nil
+}
+
+// DecodeInto decodes the next record into item
+//
+// unlike Decode, the struct item points to is reused (it is reset first), so
+// together with the ReuseRecord option reading rows does not allocate in the
+// steady state. For pointer-typed adapters, *item is allocated if it is nil.
+// Errors are handled like by Decode; the content of item is undefined after an error.
+func (d *Decoder[T]) DecodeInto(item *T) error {
+	for !d.stopped {
+		r, err := rawRecord{}, io.EOF
+		if d.More() {
+			r, err = d.nextRecord()
+		}
+		if err == io.EOF {
+			held, ok := d.nextHeld()
+			if !ok {
+				return io.EOF
+			}
+			*item = held
+			return nil
+		} else if err == nil {
+			err = d.decodeRowInto(item, r)
+		}
+		if _, ok, err := d.settle(*item, err, r.line, r.record); ok {
+			return err
+		}
+	}
+	return io.EOF
+}
+
+// InputOffset returns the byte offset following the last record read
+//
+// saved once a row is processed, it is the checkpoint to resume an interrupted
+// import from with the ResumeAt option. Like Stats, it must not be called
+// while the rows are read in parallel.
+func (d *Decoder[T]) InputOffset() int64 {
+	return d.resumeOffset
+}
+
+// Skipped returns the number of invalid rows skipped so far
+//
+// rows are skipped with the SkipInvalidRows and OnRowError options.
+func (d *Decoder[T]) Skipped() int {
+	return d.skipped
+}
+
+// Stats are the counters of a Decoder
+type Stats struct {
+	Read     int   // records read, excluding the header and the skipped lines
+	Decoded  int   // rows decoded and returned
+	Failed   int   // rows that failed, including the skipped ones
+	Skipped  int   // invalid rows skipped with the SkipInvalidRows and OnRowError options
+	Filtered int   // rows dropped by the Filter, FilterRecord and DedupeBy options
+	Bytes    int64 // bytes of the input consumed
+}
+
+// Stats returns the counters of the rows read so far
+//
+// in parallel mode (see Parallel), it must not be called before the rows
+// returned by All are exhausted or the loop over them is stopped.
+func (d *Decoder[T]) Stats() Stats {
+	return Stats{
+		Read:     d.line,
+		Decoded:  d.decoded,
+		Failed:   d.failures,
+		Skipped:  d.skipped,
+		Filtered: d.dropped + d.filtered,
+		Bytes:    d.consumed + inputOffset(d.reader),
+	}
+}
+
+// Close stops the decoder, the next calls to Decode return io.EOF
+//
+// with the CloseReader option, it closes the reader. Calling Close more than once returns ErrClosed.
+func (d *Decoder[T]) Close() error {
+	if d.closed {
+		return ErrClosed
+	}
+	d.closed = true
+	d.stopped = true
+	if d.closer == nil {
+		return nil
+	}
+	if err := d.closer.Close(); err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	return nil
+}
+
+// All returns the remaining rows of the decoder
+//
+// it is what FromCSV returns, so the decoder stays available for Stats.
+// With the CloseReader option, the decoder is closed once the rows are
+// exhausted or the loop over them is stopped, and an error closing the reader
+// is yielded last.
+func (d *Decoder[T]) All() iter.Seq2[T, error] {
+	rows := d.rows()
+	if d.closer == nil {
+		return rows
+	}
+	return func(yield func(T, error) bool) {
+		var TEmpty T
+		for item, err := range rows {
+			if !yield(item, err) {
+				d.Close()
+				return
+			}
+		}
+		if err := d.Close(); err != nil && err != ErrClosed {
+			yield(TEmpty, err)
+		}
+	}
+}
+
+// rows returns the remaining rows of the decoder, decoded sequentially or in parallel
+func (d *Decoder[T]) rows() iter.Seq2[T, error] {
+	if workers := d.adapter.options.workers; workers > 1 {
+		return d.parallel(workers)
+	}
+	return func(yield func(T, error) bool) {
+		for {
+			item, err := d.Decode()
+			if err == io.EOF {
+				return
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// read reads a record from the csv reader
+func (d *Decoder[T]) read() rawRecord {
+	offset := d.consumed + inputOffset(d.reader)
+	record, err := d.reader.Read()
+	if err == nil && d.adapter.options.allowShortRows {
+		err = d.checkWidth(record)
+	}
+	return rawRecord{record: record, offset: offset, end: d.consumed + inputOffset(d.reader), err: err}
+}
+
+// checkWidth rejects the records with more cells than the header (or the
+// first record), like encoding/csv does for all the records without AllowShortRows
+func (d *Decoder[T]) checkWidth(record []string) error {
+	if d.width == 0 {
+		d.width = len(record)
+		return nil
+	}
+	if len(record) <= d.width {
+		return nil
+	}
+	parseErr := &csv.ParseError{Err: csv.ErrFieldCount}
+	if reader, ok := d.reader.(*csv.Reader); ok {
+		parseErr.Line, parseErr.Column = reader.FieldPos(d.width)
+		parseErr.StartLine, _ = reader.FieldPos(0)
+	}
+	return parseErr
+}
+
+// inputOffset returns the offset of a record reader, 0 if it does not report it
+func inputOffset(reader RecordReader) int64 {
+	if offsetter, ok := reader.(interface{ InputOffset() int64 }); ok {
+		return offsetter.InputOffset()
+	}
+	return 0
+}
+
+// next returns the next record, dropping the footer of the SkipFooter and SkipFooterFunc options
+func (d *Decoder[T]) next() rawRecord {
+	options := d.adapter.options
+	if options.skipFooter <= 0 && options.skipFooterFunc == nil {
+		if len(d.pending) > 0 {
+			r := d.pending[0]
+			d.pending = d.pending[1:]
+			return r
+		}
+		return d.read()
+	}
+	for !d.inputEnded && !d.releasable() {
+		r := d.read()
+		var parseErr *csv.ParseError
+		if r.err == io.EOF {
+			d.inputEnded = true
+			d.dropFooter()
+			break
+		} else if r.err != nil && !errors.As(r.err, &parseErr) {
+			// the reader cannot continue, the held records are not a footer
+			d.inputEnded = true
+		}
+		if d.adapter.options.reuseRecord {
+			// the reader overwrites the record while it is held
+			r.record = slices.Clone(r.record)
+		}
+		d.pending = append(d.pending, r)
+	}
+	if len(d.pending) == 0 {
+		return rawRecord{err: io.EOF}
+	}
+	r := d.pending[0]
+	d.pending = d.pending[1:]
+	return r
+}
+
+// releasable reports whether the first pending record cannot be part of the footer,
+// i.e. it is followed by more than the last n records and by a record not matching the predicate
+func (d *Decoder[T]) releasable() bool {
+	options := d.adapter.options
+	for j := len(d.pending) - 1 - max(options.skipFooter, 0); j >= 0; j-- {
+		if options.skipFooterFunc == nil || !options.skipFooterFunc(d.pending[j].record) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropFooter drops the last n pending records, then the records matching the predicate at the end
+func (d *Decoder[T]) dropFooter() {
+	options := d.adapter.options
+	d.pending = d.pending[:max(len(d.pending)-max(options.skipFooter, 0), 0)]
+	if options.skipFooterFunc == nil {
+		return
+	}
+	for len(d.pending) > 0 && options.skipFooterFunc(d.pending[len(d.pending)-1].record) {
+		d.pending = d.pending[:len(d.pending)-1]
+	}
+}
+
+// decode reads, decodes and transforms the next record
+func (d *Decoder[T]) decode() (T, error) {
+	var TEmpty T
+	if !d.More() {
+		return TEmpty, io.EOF
+	}
+	r, err := d.nextRecord()
+	if err != nil {
+		return TEmpty, err
+	}
+	return d.decodeRow(r)
+}
+
+// nextRecord reads the next record kept by the FilterRecord option
+//
+// it returns an error if the record is malformed. Unlike More, it ignores the
+// Limit option and the state of the decoder set by Decode, so it can run
+// concurrently to the error handling of the parallel mode.
+func (d *Decoder[T]) nextRecord() (rawRecord, error) {
+	for {
+		if !d.peek() {
+			return rawRecord{}, io.EOF
+		}
+		d.peeked = false
+		d.line++
+		r := rawRecord{record: d.record, offset: d.offset, end: d.end, err: d.err, line: d.line}
+		d.resumeOffset = r.end
+		if r.err != nil {
+			return r, errors.Join(ErrReadingCSVLines, ReadingError{Line: r.line, Code: CodeMalformedRow, Err: r.err, Record: slices.Clone(r.record)}, r.err)
+		}
+		if keep := d.adapter.options.filterRecord; keep != nil && !keep(r.record) {
+			d.dropped++
+			continue
+		}
+		if d.adapter.options.collectStats {
+			d.collectStats(r.record)
+		}
+		return r, nil
+	}
+}
+
+// decodeRow decodes a record into a new row and applies the MapRead option
+func (d *Decoder[T]) decodeRow(r rawRecord) (T, error) {
+	var item, TEmpty T
+	if err := d.decodeRowInto(&item, r); err != nil {
+		return TEmpty, err
+	}
+	return item, nil
+}
+
+// decodeRowInto decodes a record into item and applies the MapRead option
+//
+// the struct item points to is reset, or allocated if the adapter is pointer-typed and *item is nil.
+func (d *Decoder[T]) decodeRowInto(item *T, r rawRecord) error {
+	c := d.adapter
+	target := reflect.ValueOf(item).Elem()
+	s := target
+	switch {
+	case target.Kind() == reflect.Interface: // runtime struct types, e.g. of NewDynamicAdapter
+		s = reflect.New(c.structType).Elem()
+	case c.pointer:
+		if s.IsNil() {
+			s.Set(reflect.New(c.structType))
+		}
+		s = s.Elem()
+	}
+	s.SetZero()
+	if err := d.decodeRecord(s, r); err != nil {
+		return err
+	}
+	if target.Kind() == reflect.Interface {
+		if c.pointer {
+			s = s.Addr()
+		}
+		target.Set(s)
+	}
+	if c.options.mapRead == nil {
+		return nil
+	}
+	mapped, err := c.options.mapRead.fn(*item)
+	if err != nil {
+		return errors.Join(
+			ErrProcessingCSVLines,
+			ReadingError{Line: r.line, Code: CodeTransform, Err: err, Record: slices.Clone(r.record)},
+			ErrTransform,
+			err,
+		)
+	}
+	*item = mapped.(T)
+	return nil
+}
+
+// decodeRecord decodes a record into the zeroed struct s
+//
+// it only reads the immutable state of the decoder, so records can be decoded concurrently.
+func (d *Decoder[T]) decodeRecord(s reflect.Value, r rawRecord) error {
+	record := r.record
+	c := d.adapter
+	if d.recordColumns != nil {
+		if s.Addr().Interface().(RecordUnmarshaler).UnmarshalCSVRecord(record, d.recordColumns) {
+			return d.validate(s, r)
+		}
+		// the fields parsed before the failing cell are decoded again
+		s.SetZero()
+	}
+	for _, f := range c.metaFields {
+		field := fieldByIndex(s, f.index)
+		switch f.meta {
+		case _TAG_META_SOURCE_FILE:
+			field.SetString(d.sourceName)
+		case _TAG_META_BYTE_OFFSET:
+			if field.CanInt() {
+				field.SetInt(r.offset)
+			} else {
+				field.SetUint(uint64(r.offset))
+			}
+		}
+	}
+	for i, f := range c.fields {
+		index := d.columns[i]
+		isFound := index >= 0 && index < len(record)
+		value := ""
+		if isFound {
+			value = record[index]
+			if f.trim {
+				value = strings.TrimSpace(value)
+			}
+			if null := c.null(&f); null != "" && value == null {
+				// null cells leave nullable fields nil
+				if f.nullable && !f.required {
+					continue
+				}
+				value = ""
+			}
+			if slices.Contains(f.empties, value) {
+				value = ""
+			}
+		} else if !f.hasDefault && (f.omitEmpty || (index < 0 && c.options.allowMissingColumns)) {
+			continue
+		} else if !f.hasDefault && (index < 0 || !c.options.allowShortRows) { // positional columns beyond the record
+			return d.fieldError(r, index, "", f, CodeFieldMissing, ErrFieldNotFound)
+		}
+		if value == "" && f.hasDefault {
+			value = f.defaultValue
+		}
+		if value == "" && f.omitEmpty && !f.required {
+			continue
+		} else if value == "" {
+			return d.fieldError(r, index, "", f, CodeEmptyValue, ErrEmptyValue)
+		}
+		field := fieldByIndex(s, f.index)
+		if err := d.decodeField(s, &c.fields[i], field, value); err != nil {
+			return d.fieldError(r, index, value, f, parseErrorCode(field.Type(), err), err)
+		}
+		if constraint, err := f.checkConstraints(field, value); err != nil {
+			return newFieldError(ReadingError{
+				Line:       r.line,
+				Code:       CodeConstraint,
+				Column:     index + 1,
+				Value:      value,
+				Record:     slices.Clone(record),
+				Constraint: constraint,
+			}, f, err)
+		}
+	}
+	if c.restField != nil && len(d.restColumns) > 0 {
+		rest := make(map[string]string, len(d.restColumns))
+		for _, i := range d.restColumns {
+			if i < len(record) { // short rows
+				rest[d.header[i]] = record[i]
+			}
+		}
+		fieldByIndex(s, c.restField.index).Set(reflect.ValueOf(rest))
+	}
+	// the hash of the row is computed once its fields are decoded
+	for _, f := range c.metaFields {
+		if f.meta == _TAG_META_ROW_HASH {
+			buf := getScratchBuffer()
+			fieldByIndex(s, f.index).SetString(c.rowHash(s, buf))
+			putScratchBuffer(buf)
+		}
+	}
+	return d.validate(s, r)
+}
+
+// validate calls the Validate method of the decoded struct s, if it has one
+func (d *Decoder[T]) validate(s reflect.Value, r rawRecord) error {
+	if v, ok := s.Addr().Interface().(validator); ok {
+		if err := v.Validate(); err != nil {
+			return errors.Join(
+				ErrProcessingCSVLines,
+				ReadingError{Line: r.line, Code: CodeValidation, Err: err, Record: slices.Clone(r.record)},
+				ErrValidation,
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// decodeField parses value into the field of the struct s,
+// with the FieldUnmarshaler of s for fields that are not flattened
+func (d *Decoder[T]) decodeField(s reflect.Value, f *field, field reflect.Value, value string) error {
+	if u, ok := s.Addr().Interface().(FieldUnmarshaler); ok && f.generated && len(f.index) == 1 {
+		handled, err := u.UnmarshalCSVField(f.index[0], value)
+		if err != nil {
+			return errors.Join(ErrParsingType, err)
+		}
+		if handled {
+			return nil
+		}
+	}
+	return f.decode(f, field, value)
+}
+
+// validator is implemented by row types validating themselves once decoded
+type validator interface {
+	Validate() error
+}
+
+// fieldError returns the error of a field of the record r, index is the column of the field or -1
+func (d *Decoder[T]) fieldError(r rawRecord, index int, value string, f field, code Code, err error) error {
+	return newFieldError(ReadingError{
+		Line:   r.line,
+		Code:   code,
+		Column: index + 1,
+		Value:  value,
+		Record: slices.Clone(r.record),
+	}, f, err)
+}
@@ -0,0 +1,523 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,invalid," + otherfakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n"
+	decoder, err := adapter.NewDecoder(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+
+	var people []Person
+	var errs []error
+	for decoder.More() {
+		person, err := decoder.Decode()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		people = append(people, person)
+	}
+	expected := []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	var readingErr ReadingError
+	if !errors.As(errs[0], &readingErr) || readingErr.Line != 2 {
+		t.Errorf("expected reading error on line 2, got %v", errs[0])
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if decoder.More() {
+		t.Errorf("expected no more records")
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](MaxErrors(2))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,email\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n"
+	rows, err := adapter.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	var errs []error
+	people := 0
+	for _, err := range rows {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		people++
+	}
+	if people != 1 || len(errs) != 2 {
+		t.Fatalf("expected 1 person and 2 errors, got %d and %v", people, errs)
+	}
+	if errors.Is(errs[0], ErrTooManyErrors) || !errors.Is(errs[1], ErrTooManyErrors) {
+		t.Errorf("expected only the last error to be ErrTooManyErrors, got %v", errs)
+	}
+
+	// the limit is reported when invalid rows are skipped too
+	adapter, err = NewCSVAdapter[Person](MaxErrors(2), SkipInvalidRows(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err = adapter.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	errs, people = nil, 0
+	for _, err := range rows {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		people++
+	}
+	if people != 1 || len(errs) != 1 || !errors.Is(errs[0], ErrTooManyErrors) {
+		t.Errorf("expected 1 person and ErrTooManyErrors, got %d and %v", people, errs)
+	}
+}
+
+func TestOnRowError(t *testing.T) {
+	var lines []int
+	var records [][]string
+	adapter, err := NewCSVAdapter[Person](OnRowError(func(line int, record []string, err error) bool {
+		if !errors.Is(err, ErrProcessingCSVLines) {
+			t.Errorf("expected ErrProcessingCSVLines, got %v", err)
+		}
+		lines = append(lines, line)
+		records = append(records, record)
+		return len(lines) < 2
+	}))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,email\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"Jane Smith,,\n" +
+		"John Doe,30," + fakemail + "\n"
+	rows, err := adapter.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	var people []Person
+	for person, err := range rows {
+		if err != nil {
+			t.Fatalf("expected no errors, got %v", err)
+		}
+		people = append(people, person)
+	}
+	expected := []Person{{othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+	if !slices.Equal(lines, []int{1, 3}) {
+		t.Errorf("expected errors on lines 1 and 3, got %v", lines)
+	}
+	if len(records) != 2 || !slices.Equal(records[0], []string{name, "invalid", fakemail}) {
+		t.Errorf("expected the raw records, got %v", records)
+	}
+}
+
+func TestSkipInvalidRows(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](SkipInvalidRows(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,email\n" +
+		"John Doe,invalid," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"\"John Doe,30\n"
+	decoder, err := adapter.NewDecoder(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var people []Person
+	for {
+		person, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no errors, got %v", err)
+		}
+		people = append(people, person)
+	}
+	expected := []Person{{othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+	if decoder.Skipped() != 2 {
+		t.Errorf("expected 2 skipped rows, got %d", decoder.Skipped())
+	}
+}
+
+type validatedPerson struct {
+	Name string `csva:"name"`
+	Age  int    `csva:"age"`
+}
+
+func (p *validatedPerson) Validate() error {
+	if p.Age > 150 {
+		return fmt.Errorf("age %d is not plausible", p.Age)
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	adapter, err := NewCSVAdapter[validatedPerson]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people, err := adapter.UnmarshalAllLenient(strings.NewReader("name,age\nJohn Doe,30\nJane Smith,250\n"))
+	if len(people) != 1 || people[0].Name != name {
+		t.Errorf("expected John Doe only, got %+v", people)
+	}
+	if !errors.Is(err, ErrValidation) || ErrorCode(err) != CodeValidation {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	readingErr, ok := AsReadingError(err)
+	if !ok || readingErr.Line != 2 || readingErr.Err.Error() != "age 250 is not plausible" {
+		t.Errorf("expected validation error at line 2, got %+v", readingErr)
+	}
+}
+
+func TestSkipFooter(t *testing.T) {
+	input := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"Total: 2\n" +
+		"\"Exported by \"\"report\"\"\"\n"
+
+	adapter, err := NewCSVAdapter[Person](SkipFooter(2))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	adapter, err = NewCSVAdapter[Person](SkipFooterFunc(func(record []string) bool {
+		return len(record) == 1
+	}))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	// records matching the predicate before the footer are decoded
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,email\nTotal: 0\n" + "John Doe,30," + fakemail + "\n"))
+	if reading, ok := AsReadingError(err); !ok || reading.Line != 1 || reading.Code != CodeMalformedRow {
+		t.Errorf("expected malformed row on line 1, got %v", err)
+	}
+
+	// the held records are not overwritten by the reader
+	adapter, err = NewCSVAdapter[Person](SkipFooter(2), ReuseRecord(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	adapter, err = NewCSVAdapter[Person](SkipFooter(5))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil || len(people) != 0 {
+		t.Errorf("expected no people, got %v, %v", people, err)
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("name,age,email\n")
+	for i := range 10 {
+		fmt.Fprintf(&input, "person%d,%d,%s\n", i, i, fakemail)
+	}
+
+	adapter, err := NewCSVAdapter[Person](Offset(3), Limit(2))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := []Person{{"person3", 3, fakemail}, {"person4", 4, fakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	adapter, err = NewCSVAdapter[Person](Offset(8), Limit(5))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(people) != 2 || people[0].Name != "person8" {
+		t.Errorf("expected person8 and person9, got %v", people)
+	}
+
+	// invalid rows are not counted, their line numbers include the offset
+	adapter, err = NewCSVAdapter[Person](Offset(1), Limit(1))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	decoder, err := adapter.NewDecoder(strings.NewReader("name,age,email\na,1,x\nb,bad,x\nc,3,x\nd,4,x\n"))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	if _, err := decoder.Decode(); err == nil {
+		t.Errorf("expected error, got nil")
+	} else if reading, ok := AsReadingError(err); !ok || reading.Line != 2 {
+		t.Errorf("expected error on line 2, got %v", err)
+	}
+	if person, err := decoder.Decode(); err != nil || person.Name != "c" {
+		t.Errorf("expected c, got %v, %v", person, err)
+	}
+	if decoder.More() {
+		t.Errorf("expected no more records after the limit")
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	input := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"Baby,one,\n"
+
+	adapter, err := NewCSVAdapter[Person](Filter(func(p Person) bool { return p.Age < age }), Limit(1))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := []Person{{othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	// discarded records are not decoded, so the invalid age is not reported
+	adapter, err = NewCSVAdapter[Person](FilterRecord(func(record []string) bool { return record[0] != "Baby" }))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected = []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	if _, err := NewCSVAdapter[Person](Filter(func(p *Person) bool { return true })); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+}
+
+func TestMapReadWrite(t *testing.T) {
+	lowercase := func(p Person) (Person, error) {
+		p.Email = strings.ToLower(p.Email)
+		return p, nil
+	}
+	checkAge := func(p Person) (Person, error) {
+		if p.Age > 150 {
+			return p, fmt.Errorf("age %d is too high", p.Age)
+		}
+		return p, nil
+	}
+	adapter, err := NewCSVAdapter[Person](MapRead(Pipe(lowercase, checkAge)), Filter(func(p Person) bool { return p.Email != "" }))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(strings.NewReader("name,age,email\nJohn Doe,30,JOHN@Example.com\nBaby,1,\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := []Person{{name, age, "john@example.com"}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,email\nJohn Doe,300,JOHN@Example.com\n"))
+	if !errors.Is(err, ErrTransform) || ErrorCode(err) != CodeTransform {
+		t.Errorf("expected %v, got %v", ErrTransform, err)
+	}
+
+	adapter, err = NewCSVAdapter[Person](MapWrite(checkAge))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if err := adapter.MarshalAll(io.Discard, []Person{{name, 300, fakemail}}); !errors.Is(err, ErrTransform) {
+		t.Errorf("expected %v, got %v", ErrTransform, err)
+	}
+
+	if _, err := NewCSVAdapter[*Person](MapWrite(checkAge)); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	input := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25,\n"
+	adapter, err := NewCSVAdapter[Person](ReuseRecord(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	decoder, err := adapter.NewDecoder(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var person Person
+	if err := decoder.DecodeInto(&person); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if expected := (Person{name, age, fakemail}); person != expected {
+		t.Errorf("expected %v, got %v", expected, person)
+	}
+	// the struct is reset, so the omitted email is cleared
+	if err := decoder.DecodeInto(&person); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if expected := (Person{othername, otherage, ""}); person != expected {
+		t.Errorf("expected %v, got %v", expected, person)
+	}
+	if err := decoder.DecodeInto(&person); err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+
+	pointerAdapter, err := NewCSVAdapter[*Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	pointerDecoder, err := pointerAdapter.NewDecoder(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var pointer *Person
+	if err := pointerDecoder.DecodeInto(&pointer); err != nil || pointer == nil || pointer.Name != name {
+		t.Errorf("expected %s, got %v, %v", name, pointer, err)
+	}
+	previous := pointer
+	if err := pointerDecoder.DecodeInto(&pointer); err != nil || pointer != previous || pointer.Name != othername {
+		t.Errorf("expected %s in the same struct, got %v, %v", othername, pointer, err)
+	}
+}
+
+func TestDecodeIntoAllocations(t *testing.T) {
+	input := "name,age,email\n" + strings.Repeat("John Doe,30,"+fakemail+"\n", 200)
+	adapter, err := NewCSVAdapter[Person](ReuseRecord(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	decoder, err := adapter.NewDecoder(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var person Person
+	// encoding/csv allocates the string holding the fields of each record
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := decoder.DecodeInto(&person); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+	})
+	if allocs > 1 {
+		t.Errorf("expected at most 1 allocation per row, got %v", allocs)
+	}
+}
+
+func TestStats(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		adapter, err := NewCSVAdapter[Person](
+			Parallel(workers),
+			SkipInvalidRows(true),
+			FilterRecord(func(record []string) bool { return record[0] != "#" }),
+			Filter(func(p Person) bool { return p.Age >= 18 }),
+		)
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+
+		data := "name,age,email\n" +
+			"John Doe,30," + fakemail + "\n" +
+			"#,0,\n" +
+			"Jane Smith,invalid," + otherfakemail + "\n" +
+			"Kid,12," + otherfakemail + "\n" +
+			"Jane Smith,25," + otherfakemail + "\n"
+		decoder, err := adapter.NewDecoder(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to create decoder: %v", err)
+		}
+		count := 0
+		for _, err := range decoder.All() {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			count++
+		}
+
+		expected := Stats{Read: 5, Decoded: 2, Failed: 1, Skipped: 1, Filtered: 2, Bytes: int64(len(data))}
+		if stats := decoder.Stats(); stats != expected || count != 2 {
+			t.Errorf("workers %d: expected %+v, got %+v (%d rows)", workers, expected, stats, count)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DedupePolicy defines which of the rows with the same key FromCSV keeps
+type DedupePolicy int
+
+const (
+	DedupeFirstWins DedupePolicy = iota // the first row is kept, the next ones are dropped
+	DedupeLastWins                      // the last row is kept, at the position of the first one
+	DedupeError                         // the next rows fail with ErrDuplicateRow
+)
+
+// dedupeKey is the key of the DedupeBy option
+type dedupeKey struct {
+	policy  DedupePolicy
+	aliases []string // columns of the key
+}
+
+// sets the columns identifying duplicate rows
+//
+// rows whose cells in the columns are all equal to the ones of a previous row
+// are handled according to policy. The text of the cells is compared, not the
+// decoded values: the trim, null and empty tag options apply, so with the trim
+// tag option " a" equals "a", but "01" and "1" differ in an int column. With
+// DedupeLastWins, the rows are only yielded once the input is read, so they
+// are all held in memory.
+func DedupeBy(policy DedupePolicy, aliases ...string) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.dedupe = &dedupeKey{policy: policy, aliases: aliases}
+	}
+}
+
+// checkDedupe checks that the columns of the DedupeBy option are fields
+func (c *CSVAdapter[T]) checkDedupe() error {
+	if c.options.dedupe == nil {
+		return nil
+	}
+	if len(c.options.dedupe.aliases) == 0 {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("DedupeBy requires at least one column"))
+	}
+	_, err := c.dedupeFields()
+	return err
+}
+
+// dedupeFields returns the indexes of the fields of the DedupeBy key
+func (c *CSVAdapter[T]) dedupeFields() ([]int, error) {
+	indexes := make([]int, len(c.options.dedupe.aliases))
+	for i, alias := range c.options.dedupe.aliases {
+		indexes[i] = slices.IndexFunc(c.fields, func(f field) bool { return f.alias == alias })
+		if indexes[i] < 0 {
+			return nil, errors.Join(ErrInvalidConfig, ErrUnknownColumn, fmt.Errorf("column %s", alias))
+		}
+	}
+	return indexes, nil
+}
+
+// dedupe applies the DedupeBy option to a decoded row
+//
+// it reports whether the row is returned now.
+func (d *Decoder[T]) dedupe(item T, line int, record []string) (bool, error) {
+	var key strings.Builder
+	for _, i := range d.dedupeFields {
+		f := &d.adapter.fields[i]
+		value := ""
+		if column := d.columns[i]; column >= 0 && column < len(record) {
+			value = d.adapter.normalizeCell(f, record[column])
+		}
+		if value == "" && f.hasDefault {
+			value = f.defaultValue
+		}
+		key.WriteString(value)
+		key.WriteByte(0)
+	}
+	policy := d.adapter.options.dedupe.policy
+	if policy == DedupeLastWins && d.adapter.pointer {
+		// DecodeInto reuses the struct the held row points to
+		copied := reflect.New(d.adapter.structType)
+		copied.Elem().Set(reflect.ValueOf(item).Elem())
+		item = copied.Interface().(T)
+	}
+	first, seen := d.seen[key.String()]
+	if !seen {
+		d.seen[key.String()] = line
+		if policy != DedupeLastWins {
+			return true, nil
+		}
+		d.heldIndex[key.String()] = len(d.held)
+		d.held = append(d.held, item)
+		d.decoded++
+		return false, nil
+	}
+	switch policy {
+	case DedupeError:
+		return false, errors.Join(ErrDuplicateRow, ReadingError{Line: line, Code: CodeDuplicateRow, Record: slices.Clone(record)}, fmt.Errorf("duplicate of line %d", first))
+	case DedupeLastWins:
+		d.held[d.heldIndex[key.String()]] = item
+	}
+	d.filtered++
+	return false, nil
+}
+
+// nextHeld returns the next row held back by DedupeLastWins
+func (d *Decoder[T]) nextHeld() (T, bool) {
+	var TEmpty T
+	if len(d.held) == 0 {
+		return TEmpty, false
+	}
+	item := d.held[0]
+	d.held = d.held[1:]
+	return item, true
+}
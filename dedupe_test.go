@@ -0,0 +1,106 @@
+package csvadapter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDedupeBy(t *testing.T) {
+	csvData := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"Johnny,31," + fakemail + "\n"
+
+	tests := []struct {
+		policy   DedupePolicy
+		expected []Person
+	}{
+		{DedupeFirstWins, []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}},
+		{DedupeLastWins, []Person{{"Johnny", 31, fakemail}, {othername, otherage, otherfakemail}}},
+	}
+	for _, tt := range tests {
+		for _, workers := range []int{1, 4} {
+			adapter, err := NewCSVAdapter[Person](DedupeBy(tt.policy, "email"), Parallel(workers))
+			if err != nil {
+				t.Fatalf("failed to create csva: %v", err)
+			}
+			people, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+			if err != nil {
+				t.Fatalf("failed to read CSV: %v", err)
+			}
+			if !slices.Equal(people, tt.expected) {
+				t.Errorf("policy %d, workers %d: expected %+v, got %+v", tt.policy, workers, tt.expected, people)
+			}
+		}
+	}
+
+	adapter, err := NewCSVAdapter[*Person](DedupeBy(DedupeLastWins, "email"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	decoder, err := adapter.NewDecoder(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var person *Person
+	var names []string
+	for decoder.DecodeInto(&person) == nil {
+		names = append(names, person.Name)
+	}
+	if expected := []string{"Johnny", othername}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestDedupeNormalizedCells(t *testing.T) {
+	type Account struct {
+		Code  string `csva:"code,trim"`
+		Level int    `csva:"level,empty=-,omitempty"`
+		Note  string `csva:"note"`
+	}
+	adapter, err := NewCSVAdapter[Account](DedupeBy(DedupeFirstWins, "code", "level"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	csvData := "code,level,note\n" +
+		"a1,,first\n" +
+		" a1 ,-,second\n" +
+		"a1,2,third\n" +
+		"a1,02,fourth\n"
+	accounts, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	// the text of the cells is compared, not the decoded values
+	if expected := []Account{{"a1", 0, "first"}, {"a1", 2, "third"}, {"a1", 2, "fourth"}}; !slices.Equal(accounts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, accounts)
+	}
+}
+
+func TestDedupeError(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](DedupeBy(DedupeError, "name", "email"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"John Doe,31," + otherfakemail + "\n" +
+		"John Doe,32," + fakemail + "\n"
+	_, err = adapter.UnmarshalAll(strings.NewReader(csvData))
+	if !errors.Is(err, ErrDuplicateRow) {
+		t.Fatalf("expected ErrDuplicateRow, got %v", err)
+	}
+	if readingErr, ok := AsReadingError(err); !ok || readingErr.Line != 3 || readingErr.Code != CodeDuplicateRow {
+		t.Errorf("unexpected reading error %+v", readingErr)
+	}
+	if !strings.Contains(err.Error(), "duplicate of line 1") {
+		t.Errorf("expected the line of the first row, got %v", err)
+	}
+
+	if _, err := NewCSVAdapter[Person](DedupeBy(DedupeError, "phone")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package csvadapter
+
+import "sync"
+
+// Dialect bundles the settings describing a flavour of csv
+//
+// the flags are always applied; the other fields are only applied if they are
+// set, their zero values leaving the options of the adapter as they are.
+type Dialect struct {
+	Comma            rune        // field separator
+	Comment          rune        // comment character
+	LazyQuotes       bool        // allow quotes in unquoted fields
+	TrimLeadingSpace bool        // ignore leading white space in fields
+	UseCRLF          bool        // write \r\n as line ending
+	NoHeader         bool        // the files have no header: the first line is data and no header is written
+	Null             string      // representation of nil values
+	Quoting          QuotePolicy // fields quoted when writing
+}
+
+// Built-in dialect profiles
+var (
+	// DefaultDialect matches the defaults of encoding/csv
+	DefaultDialect = Dialect{
+		Comma: ',',
+	}
+	// ExcelDialect matches the csv files written by Microsoft Excel
+	ExcelDialect = Dialect{
+		Comma:   ',',
+		UseCRLF: true,
+	}
+	// PostgresDialect matches COPY ... WITH (FORMAT csv, HEADER, NULL '\N')
+	PostgresDialect = Dialect{
+		Comma: ',',
+		Null:  `\N`,
+	}
+	// MySQLDialect matches the defaults of SELECT ... INTO OUTFILE and LOAD DATA INFILE
+	MySQLDialect = Dialect{
+		Comma:    '\t',
+		NoHeader: true,
+		Null:     `\N`,
+	}
+	// TSVDialect is a tab separated dialect with a header
+	TSVDialect = Dialect{
+		Comma: '\t',
+	}
+	// SemicolonEUDialect matches the csv files written by spreadsheets in
+	// locales using the comma as decimal separator
+	SemicolonEUDialect = Dialect{
+		Comma:   ';',
+		UseCRLF: true,
+	}
+	// RFC4180Dialect follows RFC 4180 strictly: CRLF line endings and no stray quotes
+	RFC4180Dialect = Dialect{
+		Comma:   ',',
+		UseCRLF: true,
+	}
+)
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[string]Dialect{
+		"default":  DefaultDialect,
+		"excel":    ExcelDialect,
+		"postgres": PostgresDialect,
+		"mysql":    MySQLDialect,
+		"tsv":      TSVDialect,
+		"eu":       SemicolonEUDialect,
+		"rfc4180":  RFC4180Dialect,
+	}
+)
+
+// DialectTSV reads and writes tab separated files
+func DialectTSV() csvAdapterOption {
+	return WithDialect(TSVDialect)
+}
+
+// DialectSemicolonEU reads and writes semicolon separated files with CRLF line endings
+func DialectSemicolonEU() csvAdapterOption {
+	return WithDialect(SemicolonEUDialect)
+}
+
+// DialectRFC4180Strict reads and writes files following RFC 4180 strictly
+//
+// quotes in unquoted fields are rejected and lines end with CRLF.
+func DialectRFC4180Strict() csvAdapterOption {
+	return WithDialect(RFC4180Dialect)
+}
+
+// Dialect returns the dialect the adapter reads and writes
+func (c *CSVAdapter[T]) Dialect() Dialect {
+	return Dialect{
+		Comma:            c.options.comma,
+		Comment:          c.options.comment,
+		LazyQuotes:       c.options.lazyQuotes,
+		TrimLeadingSpace: c.options.trimLeadingSpace,
+		UseCRLF:          c.options.useCRLF,
+		NoHeader:         c.options.noHeader || !c.options.writeHeader,
+		Null:             c.options.nullString,
+		Quoting:          c.options.quoting,
+	}
+}
+
+// RegisterDialect registers a dialect under a name so it can be reused across adapters
+//
+// registering a name twice replaces the previous dialect.
+func RegisterDialect(name string, d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[name] = d
+}
+
+// LookupDialect returns the dialect registered under a name
+func LookupDialect(name string) (Dialect, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	d, ok := dialects[name]
+	return d, ok
+}
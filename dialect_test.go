@@ -0,0 +1,207 @@
+package csvadapter
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithDialect(t *testing.T) {
+	type PersonWithNullable struct {
+		Name  string  `csva:"name"`
+		Age   int     `csva:"age"`
+		Email *string `csva:"email,omitempty"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithNullable](WithDialect(MySQLDialect))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []PersonWithNullable{
+		{name, age, stringPtr(fakemail)},
+		{othername, otherage, nil},
+	}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "John Doe\t30\t" + fakemail + "\nJane Smith\t25\t\\N\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	readAdapter, err := NewCSVAdapter[PersonWithNullable](WithDialect(PostgresDialect), Comma('\t'))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := readAdapter.FromCSV(bytes.NewReader([]byte("name\tage\temail\n" + expected)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	idx := 0
+	for person, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person.Name != people[idx].Name || (person.Email == nil) != (people[idx].Email == nil) {
+			t.Errorf("expected %+v, got %+v", people[idx], person)
+		}
+		idx++
+	}
+	if idx != len(people) {
+		t.Errorf("expected %d people, got %d", len(people), idx)
+	}
+}
+
+func TestRegisterDialect(t *testing.T) {
+	pipes := DefaultDialect
+	pipes.Comma = '|'
+	RegisterDialect("pipes", pipes)
+
+	d, ok := LookupDialect("pipes")
+	if !ok || d != pipes {
+		t.Fatalf("expected %+v, got %+v", pipes, d)
+	}
+	if _, ok := LookupDialect("unknown"); ok {
+		t.Errorf("expected unknown dialect to be missing")
+	}
+
+	adapter, err := NewCSVAdapter[Person](WithDialect(d))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values([]Person{{name, age, fakemail}})); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name|age|email\nJohn Doe|30|" + fakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
+
+func TestDialectPresets(t *testing.T) {
+	people := []Person{{name, age, fakemail}}
+
+	adapter, err := NewCSVAdapter[Person](DialectSemicolonEU())
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name;age;email\r\nJohn Doe;30;" + fakemail + "\r\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	adapter, err = NewCSVAdapter[Person](DialectTSV())
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if adapter.Dialect() != TSVDialect {
+		t.Errorf("expected %+v, got %+v", TSVDialect, adapter.Dialect())
+	}
+
+	adapter, err = NewCSVAdapter[Person](LazyQuotes(true), DialectRFC4180Strict())
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := adapter.FromCSV(bytes.NewReader([]byte("name,age,email\r\nJohn \"Doe,30," + fakemail + "\r\n")))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for _, err := range rows {
+		if err == nil {
+			t.Errorf("expected bare quote to be rejected")
+		}
+	}
+	if _, ok := LookupDialect("rfc4180"); !ok {
+		t.Errorf("expected rfc4180 dialect to be registered")
+	}
+}
+
+func TestDialectHeaderAndZeroFields(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](WithDialect(MySQLDialect))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(bytes.NewReader([]byte("John Doe\t30\t" + fakemail + "\n")))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if expected := []Person{{name, age, fakemail}}; !slices.Equal(people, expected) {
+		t.Errorf("expected %v, got %v", expected, people)
+	}
+
+	// a zero dialect keeps the header, the null string and the quoting
+	adapter, err = NewCSVAdapter[Person](NullString("NULL"), Quoting(QuoteAll), WithDialect(Dialect{Comma: ';'}))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	d := adapter.Dialect()
+	if d.NoHeader || d.Null != "NULL" || d.Quoting != QuoteAll || d.Comma != ';' {
+		t.Errorf("unexpected dialect %+v", d)
+	}
+
+	// the flags of a dialect turn off the ones set before
+	registered, _ := LookupDialect("rfc4180")
+	adapter, err = NewCSVAdapter[Person](LazyQuotes(true), TrimLeadingSpace(true), WithDialect(registered))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if d := adapter.Dialect(); d.LazyQuotes || d.TrimLeadingSpace || !d.UseCRLF {
+		t.Errorf("unexpected dialect %+v", d)
+	}
+}
+
+func TestDialectPerCall(t *testing.T) {
+	type Entry struct {
+		Name string  `csva:"name"`
+		Note *string `csva:"note,omitempty"`
+	}
+	adapter, err := NewCSVAdapter[Entry]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	rows, err := adapter.FromCSV(strings.NewReader("name,note\nJohn,\\N\n"), WithDialect(PostgresDialect))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for entry, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read entry: %v", err)
+		}
+		if entry.Note != nil {
+			t.Errorf("expected a nil note, got %q", *entry.Note)
+		}
+	}
+
+	// the dialect does not stick to the adapter
+	entries, err := adapter.UnmarshalAll(strings.NewReader("name,note\nJohn,\\N\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if entries[0].Note == nil || *entries[0].Note != `\N` {
+		t.Errorf("expected the note %q, got %v", `\N`, entries[0].Note)
+	}
+
+	writer := &bytes.Buffer{}
+	encoder, err := adapter.NewEncoder(writer, WithDialect(MySQLDialect))
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	if err := encoder.Encode(Entry{Name: "John"}); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("failed to close encoder: %v", err)
+	}
+	if expected := "John\t\\N\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
@@ -0,0 +1,97 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Diff compares two versions of a csv file, e.g. yesterday's and today's exports
+//
+// rows are matched by key: added rows are only in newer, removed rows only in
+// older, and changed rows are the newer version of the rows of both whose
+// content differs. Rows are returned in the order of their file. older is
+// held in memory, newer is streamed. Reading stops at the first error.
+//
+// only the fields mapped to columns are compared, so metadata fields (e.g. a
+// byte_offset) do not make rows differ. A key found twice in newer fails with
+// ErrDuplicateRow, as the row to compare would be ambiguous; in older, the
+// last row of a key is kept.
+func (c *CSVAdapter[T]) Diff(older, newer io.Reader, key func(T) string) (added, removed, changed []T, err error) {
+	oldRows, err := c.FromCSV(older)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var keys []string
+	previous := make(map[string]T)
+	for item, err := range oldRows {
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		k := key(item)
+		if _, ok := previous[k]; !ok {
+			keys = append(keys, k)
+		}
+		previous[k] = item
+	}
+
+	newRows, err := c.FromCSV(newer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	matched := make(map[string]bool, len(previous))
+	seen := make(map[string]bool)
+	for item, err := range newRows {
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		k := key(item)
+		if seen[k] {
+			return nil, nil, nil, errors.Join(ErrDuplicateRow, fmt.Errorf("key %q found twice in the newer file", k))
+		}
+		seen[k] = true
+		old, ok := previous[k]
+		switch {
+		case !ok:
+			added = append(added, item)
+		case !c.sameFields(old, item):
+			changed = append(changed, item)
+		}
+		if ok {
+			matched[k] = true
+		}
+	}
+	for _, k := range keys {
+		if !matched[k] {
+			removed = append(removed, previous[k])
+		}
+	}
+	return added, removed, changed, nil
+}
+
+// sameFields reports whether the fields mapped to columns of a and b are equal
+func (c *CSVAdapter[T]) sameFields(a, b T) bool {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if c.pointer {
+		if av.IsNil() || bv.IsNil() {
+			return av.IsNil() == bv.IsNil()
+		}
+		av, bv = av.Elem(), bv.Elem()
+	}
+	fields := c.fields
+	if c.restField != nil {
+		fields = append(fields[:len(fields):len(fields)], *c.restField)
+	}
+	for _, f := range fields {
+		af, aErr := av.FieldByIndexErr(f.index)
+		bf, bErr := bv.FieldByIndexErr(f.index)
+		if (aErr == nil) != (bErr == nil) {
+			return false
+		}
+		if aErr == nil && !reflect.DeepEqual(af.Interface(), bf.Interface()) {
+			return false
+		}
+	}
+	return true
+}
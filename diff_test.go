@@ -0,0 +1,73 @@
+package csvadapter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	older := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n" +
+		"Bob,40,\n"
+	newer := "name,age,email\n" +
+		"Alice,22,\n" +
+		"John Doe,31," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n"
+	added, removed, changed, err := adapter.Diff(strings.NewReader(older), strings.NewReader(newer), func(p Person) string { return p.Name })
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	if expected := []Person{{"Alice", 22, ""}}; !slices.Equal(added, expected) {
+		t.Errorf("expected added %+v, got %+v", expected, added)
+	}
+	if expected := []Person{{"Bob", 40, ""}}; !slices.Equal(removed, expected) {
+		t.Errorf("expected removed %+v, got %+v", expected, removed)
+	}
+	if expected := []Person{{name, 31, fakemail}}; !slices.Equal(changed, expected) {
+		t.Errorf("expected changed %+v, got %+v", expected, changed)
+	}
+
+	_, _, _, err = adapter.Diff(strings.NewReader(older), strings.NewReader("name,age,email\nJohn Doe,invalid,\n"), func(p Person) string { return p.Name })
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestDiffMappedFields(t *testing.T) {
+	type Located struct {
+		Name   string `csva:"name"`
+		Age    int    `csva:"age"`
+		Offset int64  `csva:"-,byte_offset"`
+	}
+	adapter, err := NewCSVAdapter[Located]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	key := func(l Located) string { return l.Name }
+
+	older := "name,age\nJohn,30\nJane,25\n"
+	newer := "name,age\nJane,25\nJohn,31\n"
+	added, removed, changed, err := adapter.Diff(strings.NewReader(older), strings.NewReader(newer), key)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("expected no added or removed rows, got %+v and %+v", added, removed)
+	}
+	if len(changed) != 1 || changed[0].Name != "John" {
+		t.Errorf("expected only John changed, got %+v", changed)
+	}
+
+	_, _, _, err = adapter.Diff(strings.NewReader(older), strings.NewReader("name,age\nJohn,30\nJohn,31\n"), key)
+	if !errors.Is(err, ErrDuplicateRow) {
+		t.Errorf("expected ErrDuplicateRow, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// Column describes a column of a DynamicAdapter
+type Column struct {
+	Name    string       // name of the column in the csv
+	Type    reflect.Type // type of the values, e.g. reflect.TypeFor[int]()
+	Options string       // tag options without the alias, e.g. "omitempty,sep=;"
+}
+
+// DynamicAdapter adapts csv files whose schema is only known at runtime
+//
+// rows are read into and written from maps keyed by column name. The values
+// have the types of the columns, missing and nil values are zero values.
+type DynamicAdapter struct {
+	schema  []Column
+	adapter *CSVAdapter[any]
+}
+
+// NewDynamicAdapter creates a DynamicAdapter for the columns of schema
+func NewDynamicAdapter(schema []Column, options ...csvAdapterOption) (*DynamicAdapter, error) {
+	structFields := make([]reflect.StructField, len(schema))
+	for i, column := range schema {
+		if column.Type == nil {
+			return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("column %s has no type", column.Name))
+		}
+		name := "F" + strconv.Itoa(i)
+		structFields[i] = reflect.StructField{
+			Name: name,
+			Type: column.Type,
+			Tag:  reflect.StructTag(_TAG + ":" + strconv.Quote(name+","+column.Options)),
+		}
+	}
+	// column names are not written in the tags, so they can contain "," and "="
+	names := make([]string, len(schema))
+	for i, column := range schema {
+		names[i] = column.Name
+	}
+	adapter, err := newCSVAdapter[any](reflect.StructOf(structFields), names, options)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicAdapter{schema: schema, adapter: adapter}, nil
+}
+
+// Schema returns the columns of the adapter
+func (d *DynamicAdapter) Schema() []Column {
+	return d.schema
+}
+
+// FromCSV reads a csv file into maps keyed by column name
+func (d *DynamicAdapter) FromCSV(reader io.Reader) (iter.Seq2[map[string]any, error], error) {
+	rows, err := d.adapter.FromCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(map[string]any, error) bool) {
+		for row, err := range rows {
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			if !yield(d.toMap(reflect.ValueOf(row)), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ToCSV writes maps keyed by column name to a csv file
+//
+// keys that are not columns of the schema and values that are not
+// assignable to the type of their column are rejected.
+func (d *DynamicAdapter) ToCSV(writer io.Writer, data iter.Seq[map[string]any]) error {
+	var err error
+	items := func(yield func(any) bool) {
+		line := 0
+		for row := range data {
+			line++
+			var item reflect.Value
+			item, err = d.fromMap(row, line)
+			if err != nil || !yield(item.Interface()) {
+				return
+			}
+		}
+	}
+	if writeErr := d.adapter.ToCSV(writer, items); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// Profile describes the columns of a csv file like CSVAdapter.Profile, with the types of the schema
+func (d *DynamicAdapter) Profile(reader io.Reader) (*Report, error) {
+	return d.adapter.Profile(reader)
+}
+
+// toMap converts a row struct to a map
+func (d *DynamicAdapter) toMap(v reflect.Value) map[string]any {
+	row := make(map[string]any, len(d.schema))
+	for i, column := range d.schema {
+		row[column.Name] = v.Field(i).Interface()
+	}
+	return row
+}
+
+// fromMap converts a map to a row struct
+func (d *DynamicAdapter) fromMap(row map[string]any, line int) (reflect.Value, error) {
+	v := reflect.New(d.adapter.structType).Elem()
+	matched := 0
+	for i, column := range d.schema {
+		value, ok := row[column.Name]
+		if !ok {
+			continue
+		}
+		matched++
+		if value == nil {
+			continue
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.Type().AssignableTo(column.Type) {
+			return reflect.Value{}, errors.Join(
+				ErrProcessingCSVLines,
+				ReadingError{Line: line, Field: column.Name, FieldAlias: column.Name, Code: CodeUnsupportedType, Column: i + 1},
+				ErrUnprocessableType,
+				fmt.Errorf("column %s: %T is not a %s", column.Name, value, column.Type),
+			)
+		}
+		v.Field(i).Set(rv)
+	}
+	if matched < len(row) {
+		for key := range row {
+			if !d.hasColumn(key) {
+				return reflect.Value{}, errors.Join(
+					ErrProcessingCSVLines,
+					ReadingError{Line: line, Code: CodeUnknownColumn},
+					ErrUnknownColumn,
+					fmt.Errorf("column %s", key),
+				)
+			}
+		}
+	}
+	return v, nil
+}
+
+// hasColumn reports whether the schema has a column with the given name
+func (d *DynamicAdapter) hasColumn(name string) bool {
+	for _, column := range d.schema {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDynamicAdapter(t *testing.T) {
+	schema := []Column{
+		{Name: "full name", Type: reflect.TypeFor[string]()},
+		{Name: "age", Type: reflect.TypeFor[int]()},
+		{Name: "tags", Type: reflect.TypeFor[[]string](), Options: "omitempty,sep=;"},
+		{Name: "score, %", Type: reflect.TypeFor[*float64](), Options: "omitempty"},
+	}
+	adapter, err := NewDynamicAdapter(schema)
+	if err != nil {
+		t.Fatalf("failed to create dynamic adapter: %v", err)
+	}
+
+	data := "full name,age,tags,\"score, %\"\nJohn Doe,30,a;b,\nJane Smith,25,,99.5\n"
+	rows, err := adapter.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	var got []map[string]any
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read row: %v", err)
+		}
+		got = append(got, row)
+	}
+	if len(got) != 2 || got[0]["full name"] != name || got[0]["age"] != age || !slices.Equal(got[0]["tags"].([]string), []string{"a", "b"}) {
+		t.Fatalf("unexpected rows %+v", got)
+	}
+	if score := got[1]["score, %"].(*float64); score == nil || *score != 99.5 {
+		t.Errorf("expected score 99.5, got %v", got[1]["score, %"])
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(got)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "full name,age,tags,\"score, %\"\nJohn Doe,30,a;b,\nJane Smith,25,,99.500000\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	_, err = adapter.FromCSV(strings.NewReader("full name,tags\nJohn Doe,a\n"))
+	if !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+
+	wrongType := maps.Clone(got[0])
+	wrongType["age"] = "thirty"
+	if err := adapter.ToCSV(&bytes.Buffer{}, slices.Values([]map[string]any{wrongType})); !errors.Is(err, ErrUnprocessableType) {
+		t.Errorf("expected ErrUnprocessableType, got %v", err)
+	}
+	unknown := maps.Clone(got[0])
+	unknown["email"] = fakemail
+	if err := adapter.ToCSV(&bytes.Buffer{}, slices.Values([]map[string]any{unknown})); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+
+	if _, err := NewDynamicAdapter([]Column{{Name: "age"}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestDynamicAdapterAliasOptions(t *testing.T) {
+	schema := []Column{
+		{Name: "name", Type: reflect.TypeFor[string]()},
+		{Name: "age", Type: reflect.TypeFor[int]()},
+	}
+	adapter, err := NewDynamicAdapter(schema, DedupeBy(DedupeFirstWins, "name"), ColumnOrder("age", "name"))
+	if err != nil {
+		t.Fatalf("failed to create dynamic adapter: %v", err)
+	}
+	rows, err := adapter.FromCSV(strings.NewReader("name,age\nAlice,30\nAlice,31\nBob,25\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var names []any
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names = append(names, row["name"])
+	}
+	if !slices.Equal(names, []any{"Alice", "Bob"}) {
+		t.Errorf("expected Alice and Bob, got %v", names)
+	}
+
+	var output bytes.Buffer
+	if err := adapter.ToCSV(&output, slices.Values([]map[string]any{{"name": "Alice", "age": 30}})); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if expected := "age,name\n30,Alice\n"; output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestDynamicAdapterFlatten(t *testing.T) {
+	type Address struct {
+		Street string `csva:"street"`
+		City   string `csva:"city"`
+	}
+	schema := []Column{
+		{Name: "name", Type: reflect.TypeFor[string]()},
+		{Name: "home", Type: reflect.TypeFor[Address](), Options: "flatten"},
+	}
+	adapter, err := NewDynamicAdapter(schema)
+	if err != nil {
+		t.Fatalf("failed to create dynamic adapter: %v", err)
+	}
+	data := "name,home_street,home_city\nAlice,Main St,Springfield\n"
+	rows, err := adapter.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var got []map[string]any
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read row: %v", err)
+		}
+		got = append(got, row)
+	}
+	if len(got) != 1 || got[0]["home"] != (Address{"Main St", "Springfield"}) {
+		t.Fatalf("unexpected rows %+v", got)
+	}
+
+	var output bytes.Buffer
+	if err := adapter.ToCSV(&output, slices.Values(got)); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if output.String() != data {
+		t.Errorf("expected %q, got %q", data, output.String())
+	}
+}
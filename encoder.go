@@ -0,0 +1,345 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Encoder writes structs to a csv file one record at a time
+//
+// it is the push-based counterpart of ToCSV. The header is written with the
+// first record (or on Close if there is none), so Close must always be called.
+type Encoder[T any] struct {
+	adapter     *CSVAdapter[T]
+	writer      RecordWriter
+	closeOutput func() error
+	positions   []int    // position of each field in the record
+	width       int      // number of columns of the fields
+	restColumns []string // columns of the rest field, taken from the first record
+
+	header        bool // if the header is written
+	nullCells     bool // if nil and empty values are written as empty cells, for formats with their own null (e.g. SQL)
+	headerWritten bool
+	line          int // number of the last record written
+	written       int // number of records written successfully
+	closed        bool
+
+	generated     bool      // if the structs implement FieldMarshaler
+	recordColumns []int     // positions of the fields of the structs implementing RecordMarshaler, nil if not used
+	release       func()    // releases the buffer of the writer
+	record        *[]string // record reused across rows
+	buf           *[]byte   // scratch buffer the cells are formatted in
+}
+
+// NewEncoder creates an Encoder writing to writer
+//
+// closing the encoder does not close writer. options apply to this encoder
+// only, on top of the options of the adapter.
+func (c *CSVAdapter[T]) NewEncoder(writer io.Writer, options ...csvAdapterOption) (*Encoder[T], error) {
+	c, err := c.with(options)
+	if err != nil {
+		return nil, err
+	}
+	output, closeOutput, err := c.options.openWriter(writer)
+	if err != nil {
+		return nil, err
+	}
+	csvWriter, release := c.options.newRecordWriter(output)
+	encoder := c.newEncoder(csvWriter, release, closeOutput)
+	if q, ok := csvWriter.(*quotingWriter); ok {
+		q.numeric = encoder.numericColumns()
+	}
+	return encoder, nil
+}
+
+// newEncoder creates an Encoder writing the records to writer
+func (c *CSVAdapter[T]) newEncoder(writer RecordWriter, release func(), closeOutput func() error) *Encoder[T] {
+	positions := make([]int, len(c.fields))
+	width := len(c.fields)
+	for i, f := range c.fields {
+		positions[i] = i
+		if c.positional || c.fallback {
+			positions[i] = f.column
+			width = max(width, f.column+1)
+		}
+	}
+
+	var recordColumns []int
+	if c.structType.Implements(reflect.TypeFor[RecordMarshaler]()) {
+		recordColumns = c.recordColumns(positions)
+	}
+
+	return &Encoder[T]{
+		adapter:       c,
+		writer:        writer,
+		closeOutput:   closeOutput,
+		positions:     positions,
+		width:         width,
+		header:        c.options.writeHeader && !c.positional,
+		generated:     c.structType.Implements(reflect.TypeFor[FieldMarshaler]()),
+		recordColumns: recordColumns,
+		release:       release,
+		buf:           getScratchBuffer(),
+	}
+}
+
+// writeHeader writes the header, unless the adapter is positional or the header is disabled
+func (e *Encoder[T]) writeHeader() error {
+	e.headerWritten = true
+	// positional adapters write csv files without a header
+	if !e.header {
+		return nil
+	}
+	header := append(e.adapter.Header(), e.restColumns...)
+	if name := e.adapter.options.rowHashColumn; name != "" {
+		header = append(header, name)
+	}
+	var err error
+	if q, ok := e.writer.(*quotingWriter); ok {
+		err = q.writeRecord(header, nil) // the cells of the header are not numbers
+	} else {
+		err = e.writer.Write(header)
+	}
+	if err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	return nil
+}
+
+// Encode writes a struct as the next record
+func (e *Encoder[T]) Encode(item T) error {
+	if e.closed {
+		return ErrClosed
+	}
+	c := e.adapter
+	e.line++
+	if c.options.mapWrite != nil {
+		mapped, err := c.options.mapWrite.fn(item)
+		if err != nil {
+			return errors.Join(ErrTransform, ReadingError{Line: e.line, Code: CodeTransform, Err: err}, err)
+		}
+		item = mapped.(T)
+	}
+	itemV := reflect.ValueOf(item)
+	if c.pointer {
+		if itemV.IsNil() {
+			return errors.Join(ErrNilRecord, ReadingError{Line: e.line, Code: CodeNilRecord})
+		}
+		itemV = itemV.Elem()
+	}
+	var rest map[string]string
+	if c.restField != nil {
+		if field, err := itemV.FieldByIndexErr(c.restField.index); err == nil {
+			rest = field.Interface().(map[string]string)
+		}
+	}
+	if !e.headerWritten {
+		e.restColumns = slices.Sorted(maps.Keys(rest))
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+	hashed := c.options.rowHashColumn != ""
+	if e.record == nil {
+		width := e.width + len(e.restColumns)
+		if hashed {
+			width++
+		}
+		e.record = getRecord(width)
+	}
+	record := *e.record
+	clear(record)
+	if e.recordColumns == nil || !itemV.Interface().(RecordMarshaler).MarshalCSVRecord(record, e.recordColumns) {
+		if err := e.encodeFields(itemV, record); err != nil {
+			return err
+		}
+	}
+	for column, value := range rest {
+		i := slices.Index(e.restColumns, column)
+		if i < 0 {
+			return newFieldError(ReadingError{Line: e.line, Code: CodeUnknownColumn}, *c.restField, errors.Join(ErrUnknownColumn, fmt.Errorf("column %s", column)))
+		}
+		record[e.width+i] = value
+	}
+	if hashed {
+		record[len(record)-1] = c.rowHash(itemV, e.buf)
+	}
+	if err := e.writer.Write(record); err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	e.written++
+	if n := c.options.flushEvery; n > 0 && e.written%n == 0 {
+		return e.Flush()
+	}
+	return nil
+}
+
+// encodeFields formats the fields of the struct s into record
+func (e *Encoder[T]) encodeFields(s reflect.Value, record []string) error {
+	c := e.adapter
+	var marshaler FieldMarshaler
+	if e.generated {
+		marshaler = s.Interface().(FieldMarshaler)
+	}
+	for i, f := range c.fields {
+		field, err := s.FieldByIndexErr(f.index)
+		if err != nil || isNull(field) { // nil pointer to a flattened struct or nil value
+			record[e.positions[i]] = e.nullValue(&c.fields[i])
+			continue
+		}
+		str, err := encodeField(marshaler, &c.fields[i], field, e.buf)
+		if err != nil {
+			code := CodeFormatValue
+			if errors.Is(err, ErrUnprocessableType) {
+				code = CodeUnsupportedType
+			}
+			return newFieldError(ReadingError{Line: e.line, Code: code, Column: e.positions[i] + 1}, f, err)
+		}
+		if str == "" && f.omitEmpty {
+			if !e.nullCells {
+				str = f.emptyValue()
+			}
+			record[e.positions[i]] = str
+			continue
+		} else if str == "" {
+			return newFieldError(ReadingError{Line: e.line, Code: CodeEmptyValue, Column: e.positions[i] + 1}, f, ErrEmptyValue)
+		}
+		record[e.positions[i]] = str
+	}
+	return nil
+}
+
+// nullValue returns the cell written for the nil values of f
+func (e *Encoder[T]) nullValue(f *field) string {
+	if e.nullCells {
+		return ""
+	}
+	return e.adapter.nullValue(f)
+}
+
+// Flush writes the buffered records to the underlying writer
+func (e *Encoder[T]) Flush() error {
+	if e.closed {
+		return ErrClosed
+	}
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	return nil
+}
+
+// Close writes the header if no record was written, flushes the buffered
+// records and closes the layers opened by the encoder (e.g. compression)
+//
+// calling Close more than once returns ErrClosed.
+func (e *Encoder[T]) Close() error {
+	if e.closed {
+		return ErrClosed
+	}
+	var err error
+	if !e.headerWritten {
+		err = e.writeHeader()
+	}
+	if err == nil {
+		err = e.Flush()
+	}
+	e.closed = true
+	err = errors.Join(err, e.closeOutput())
+
+	// the scratch state is no longer used once closed
+	e.release()
+	putScratchBuffer(e.buf)
+	if e.record != nil {
+		putRecord(e.record)
+	}
+	return err
+}
+
+// columns returns the keys of the records written by e, and if their cells are
+// literals (numbers and booleans formatted by default, i.e. in base 10 without
+// separators, and json fields if json is set)
+func (e *Encoder[T]) columns(json bool) ([]string, []bool) {
+	c := e.adapter
+	keys := make([]string, e.width+len(e.restColumns))
+	literal := make([]bool, len(keys))
+	for i, f := range c.fields {
+		keys[e.positions[i]] = f.alias
+		literal[e.positions[i]] = c.literalKind(&c.fields[i]) != reflect.Invalid || (json && f.json)
+	}
+	copy(keys[e.width:], e.restColumns)
+	if name := c.options.rowHashColumn; name != "" {
+		keys, literal = append(keys, name), append(literal, false)
+	}
+	return keys, literal
+}
+
+// numericColumns returns the columns of number fields of the records written by e
+func (e *Encoder[T]) numericColumns() []numericColumn {
+	c := e.adapter
+	numeric := make([]numericColumn, e.width)
+	for i := range c.fields {
+		f := &c.fields[i]
+		kind := c.literalKind(f)
+		if kind == reflect.Invalid || kind == reflect.Bool {
+			continue
+		}
+		column := numericColumn{numeric: true, sentinels: f.empties}
+		if null := c.null(f); null != "" {
+			column.sentinels = append(slices.Clip(column.sentinels), null)
+		}
+		numeric[e.positions[i]] = column
+	}
+	return numeric
+}
+
+// literalKind returns the kind of a number or bool field formatted by default,
+// i.e. in base 10 without separators, reflect.Invalid for the other fields
+func (c *CSVAdapter[T]) literalKind(f *field) reflect.Kind {
+	t := c.structType.FieldByIndex(f.index).Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isSQLNull(t) {
+		t = t.Field(0).Type
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		if f.converter == nil && !isTextType(t) && f.trueValues == nil &&
+			(f.base == 0 || f.base == 10) && f.thousands == 0 && f.decimal == 0 {
+			return t.Kind()
+		}
+	}
+	return reflect.Invalid
+}
+
+// encodeAll encodes the structs of data and closes the encoder
+//
+// the errors of closing the encoder (e.g. flushing to a full disk) are
+// returned too when a struct cannot be encoded.
+func encodeAll[T any](encoder *Encoder[T], data iter.Seq[T]) error {
+	for item := range data {
+		if err := encoder.Encode(item); err != nil {
+			return errors.Join(err, encoder.Close())
+		}
+	}
+	return encoder.Close()
+}
+
+// encodeField formats the field of a struct, with the FieldMarshaler
+// of the struct for fields that are not flattened
+func encodeField(marshaler FieldMarshaler, f *field, field reflect.Value, buf *[]byte) (string, error) {
+	if marshaler != nil && f.generated && len(f.index) == 1 {
+		str, handled, err := marshaler.MarshalCSVField(f.index[0])
+		if err != nil || handled {
+			return str, err
+		}
+	}
+	return f.encode(f, field, buf)
+}
@@ -0,0 +1,126 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	writer := &bytes.Buffer{}
+	encoder, err := adapter.NewEncoder(writer)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	if err := encoder.Encode(Person{name, age, fakemail}); err != nil {
+		t.Fatalf("failed to encode person: %v", err)
+	}
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	expected := "name,age,email\nJohn Doe,30," + fakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	if err := encoder.Encode(Person{othername, otherage, otherfakemail}); err != nil {
+		t.Fatalf("failed to encode person: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("failed to close encoder: %v", err)
+	}
+	expected += "Jane Smith,25," + otherfakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	if err := encoder.Encode(Person{name, age, fakemail}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if err := encoder.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestEncoderEmpty(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	writer := &bytes.Buffer{}
+	encoder, err := adapter.NewEncoder(writer)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("failed to close encoder: %v", err)
+	}
+	if writer.String() != "name,age,email\n" {
+		t.Errorf("expected header only, got %q", writer.String())
+	}
+}
+
+func TestEncoderAllocations(t *testing.T) {
+	type Row struct {
+		Name   string  `csva:"name"`
+		Age    int     `csva:"age"`
+		Score  float64 `csva:"score"`
+		Active bool    `csva:"active"`
+	}
+	adapter, err := NewCSVAdapter[Row]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	encoder, err := adapter.NewEncoder(io.Discard)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	row := Row{name, 1234, 12.5, true}
+	// the row passed to reflect, the formatted age and score
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := encoder.Encode(row); err != nil {
+			t.Fatalf("failed to encode row: %v", err)
+		}
+	})
+	if allocs > 3 {
+		t.Errorf("expected at most 3 allocations per row, got %v", allocs)
+	}
+}
+
+func TestEncoderConcurrent(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	expected := "name,age,email\nJohn Doe,30," + fakemail + "\n"
+	// encoders share pooled buffers, which must not leak between them
+	errs := make(chan error, 8)
+	for range 8 {
+		go func() {
+			for range 100 {
+				writer := &bytes.Buffer{}
+				if err := adapter.MarshalAll(writer, []Person{{name, age, fakemail}}); err != nil {
+					errs <- err
+					return
+				}
+				if writer.String() != expected {
+					errs <- errors.New("unexpected output " + writer.String())
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range 8 {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
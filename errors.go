@@ -1,20 +1,276 @@
 package csvadapter
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 )
 
+// Code is a stable machine-readable error code
+type Code string
+
+const (
+	CodeUnknown         Code = "UNKNOWN"
+	CodeEmptyValue      Code = "EMPTY_VALUE"
+	CodeFieldMissing    Code = "FIELD_MISSING"
+	CodeUnknownColumn   Code = "UNKNOWN_COLUMN"
+	CodeDuplicateColumn Code = "DUPLICATE_COLUMN"
+	CodeParseInt        Code = "PARSE_INT"
+	CodeParseUint       Code = "PARSE_UINT"
+	CodeParseFloat      Code = "PARSE_FLOAT"
+	CodeParseComplex    Code = "PARSE_COMPLEX"
+	CodeParseBool       Code = "PARSE_BOOL"
+	CodeParseText       Code = "PARSE_TEXT"
+	CodeFormatValue     Code = "FORMAT_VALUE"
+	CodeUnsupportedType Code = "UNSUPPORTED_TYPE"
+	CodeMalformedRow    Code = "MALFORMED_ROW"
+	CodeInvalidTag      Code = "INVALID_TAG"
+	CodeNotStruct       Code = "NOT_STRUCT"
+	CodeInvalidConfig   Code = "INVALID_CONFIG"
+	CodeValidation      Code = "VALIDATION"
+	CodeConstraint      Code = "CONSTRAINT"
+	CodeNilRecord       Code = "NIL_RECORD"
+	CodeTransform       Code = "TRANSFORM"
+	CodeHeaderMismatch  Code = "HEADER_MISMATCH"
+	CodeDuplicateRow    Code = "DUPLICATE_ROW"
+	CodeDuplicateValue  Code = "DUPLICATE_VALUE"
+	CodeUnknownVariant  Code = "UNKNOWN_VARIANT"
+	CodeParseValue      Code = "PARSE_VALUE"
+	CodeCharset         Code = "CHARSET"
+	CodeUnquotableField Code = "UNQUOTABLE_FIELD"
+	CodeTooManyErrors   Code = "TOO_MANY_ERRORS"
+	CodeClosed          Code = "CLOSED"
+	CodeIO              Code = "IO_ERROR"
+	CodeProcessing      Code = "PROCESSING_ERROR"
+)
+
+// ReadingError is the row context of an error
 type ReadingError struct {
 	Line       int
 	Field      string
 	FieldAlias string
+	Code       Code
+	Column     int      // number of the column in the csv (starting at 1), 0 if unknown
+	Value      string   // offending cell, empty for writes and missing cells
+	Err        error    // cause of the error
+	Record     []string // copy of the raw record when reading, nil if it could not be parsed
+	Constraint string   // violated tag constraint, e.g. "max=150"
 }
 
 func (r ReadingError) Error() string {
-	return fmt.Sprintf(
+	if r.Field == "" {
+		return fmt.Sprintf("error reading line %d", r.Line)
+	}
+	msg := fmt.Sprintf(
 		"error reading field %s (%s) at line %d",
 		r.Field,
 		r.FieldAlias,
 		r.Line,
 	)
+	if r.Column > 0 {
+		msg += fmt.Sprintf(", column %d", r.Column)
+	}
+	if r.Value != "" {
+		msg += fmt.Sprintf(", value %q", r.Value)
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the error
+func (r ReadingError) Unwrap() error {
+	return r.Err
+}
+
+// AsReadingError returns the row context of err, if it has one
+func AsReadingError(err error) (ReadingError, bool) {
+	var readingErr ReadingError
+	ok := errors.As(err, &readingErr)
+	return readingErr, ok
+}
+
+// newFieldError joins err with the row context r, completed with the field and the cause
+func newFieldError(r ReadingError, f field, err error) error {
+	r.Field = f.name
+	r.FieldAlias = f.alias
+	r.Err = err
+	return errors.Join(ErrProcessingCSVLines, r, err)
+}
+
+// parseErrorCode returns the code of an error produced while parsing a value of type t
+func parseErrorCode(t reflect.Type, err error) Code {
+	if errors.Is(err, ErrUnprocessableType) {
+		return CodeUnsupportedType
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isSQLNull(t) {
+		t = t.Field(0).Type
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return CodeParseInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return CodeParseUint
+	case reflect.Float32, reflect.Float64:
+		return CodeParseFloat
+	case reflect.Complex64, reflect.Complex128:
+		return CodeParseComplex
+	case reflect.Bool:
+		return CodeParseBool
+	default:
+		return CodeParseText
+	}
+}
+
+// sentinelCodes maps sentinel errors to codes for errors without row context
+var sentinelCodes = map[error]Code{
+	ErrEmptyValue:          CodeEmptyValue,
+	ErrFieldNotFound:       CodeFieldMissing,
+	ErrUnknownColumn:       CodeUnknownColumn,
+	ErrDuplicateColumn:     CodeDuplicateColumn,
+	ErrAliasNotFound:       CodeInvalidTag,
+	ErrInvalidTag:          CodeInvalidTag,
+	ErrUnsupportedTag:      CodeInvalidTag,
+	ErrUnprocessableType:   CodeUnsupportedType,
+	ErrorNotStruct:         CodeNotStruct,
+	ErrInvalidConfig:       CodeInvalidConfig,
+	ErrReadingCSVLines:     CodeMalformedRow,
+	ErrValidation:          CodeValidation,
+	ErrConstraintViolation: CodeConstraint,
+	ErrNilRecord:           CodeNilRecord,
+	ErrTransform:           CodeTransform,
+	ErrHeaderMismatch:      CodeHeaderMismatch,
+	ErrDuplicateRow:        CodeDuplicateRow,
+	ErrDuplicateValue:      CodeDuplicateValue,
+	ErrUnknownVariant:      CodeUnknownVariant,
+	ErrParsingType:         CodeParseValue,
+	ErrWrongNumberOfFields: CodeMalformedRow,
+	ErrCharset:             CodeCharset,
+	ErrUnquotableField:     CodeUnquotableField,
+	ErrTooManyErrors:       CodeTooManyErrors,
+	ErrClosed:              CodeClosed,
+	ErrReadingCSV:          CodeIO,
+	ErrProcessingCSVLines:  CodeProcessing,
+}
+
+// ErrorCode returns the code of an error returned by the adapter
+//
+// the code of the row context is preferred, then the code of the most specific sentinel error.
+// CodeUnknown is returned if err carries neither.
+func ErrorCode(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var readingErr ReadingError
+	if errors.As(err, &readingErr) && readingErr.Code != "" {
+		return readingErr.Code
+	}
+	for _, sentinel := range sentinels {
+		if code, ok := sentinelCodes[sentinel]; ok && errors.Is(err, sentinel) {
+			return code
+		}
+	}
+	return CodeUnknown
+}
+
+// ErrorDetails describes an error returned by the adapter so it can be
+// rendered into a user-facing message
+type ErrorDetails struct {
+	Err      error         // the original error
+	Reading  *ReadingError // row context of the error, nil if there is none
+	Sentinel error         // most specific sentinel error of this package in Err, nil if there is none
+	Code     Code          // machine-readable code of Err
+}
+
+// ErrorRenderer renders an error returned by the adapter into a user-facing message
+type ErrorRenderer func(details ErrorDetails) string
+
+// sentinels ordered from the most to the least specific
+var sentinels = []error{
+	ErrTooManyErrors,
+	ErrClosed,
+	ErrCharset,
+	ErrUnquotableField,
+	ErrValidation,
+	ErrConstraintViolation,
+	ErrNilRecord,
+	ErrTransform,
+	ErrHeaderMismatch,
+	ErrDuplicateRow,
+	ErrDuplicateValue,
+	ErrUnknownVariant,
+	ErrEmptyValue,
+	ErrParsingType,
+	ErrUnprocessableType,
+	ErrFieldNotFound,
+	ErrUnknownColumn,
+	ErrDuplicateColumn,
+	ErrAliasNotFound,
+	ErrInvalidTag,
+	ErrUnsupportedTag,
+	ErrorNotStruct,
+	ErrInvalidConfig,
+	ErrWrongNumberOfFields,
+	ErrReadingCSVLines,
+	ErrReadingCSV,
+	ErrProcessingCSVLines,
+}
+
+// NewErrorDetails extracts the row context and the sentinel error from err
+func NewErrorDetails(err error) ErrorDetails {
+	details := ErrorDetails{Err: err, Code: ErrorCode(err)}
+	var readingErr ReadingError
+	if errors.As(err, &readingErr) {
+		details.Reading = &readingErr
+	}
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			details.Sentinel = sentinel
+			break
+		}
+	}
+	return details
+}
+
+// RenderError renders err with the renderer set by RenderErrors
+//
+// when no renderer is set, the error message is returned as is.
+func (c *CSVAdapter[T]) RenderError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if c.options.errorRenderer == nil {
+		return err.Error()
+	}
+	return c.options.errorRenderer(NewErrorDetails(err))
+}
+
+// RowErrors collects the errors of every invalid row of a csv file
+type RowErrors struct {
+	Errors []error // errors of the rows, in the order of the file
+}
+
+func (r *RowErrors) Error() string {
+	if len(r.Errors) == 1 {
+		return r.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d invalid rows, first: %v", len(r.Errors), r.Errors[0])
+}
+
+// Unwrap returns the errors of the rows, so errors.Is and errors.As inspect all of them
+func (r *RowErrors) Unwrap() []error {
+	return r.Errors
+}
+
+// ReadingErrors returns the row context of every error
+func (r *RowErrors) ReadingErrors() []ReadingError {
+	readingErrs := make([]ReadingError, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		var readingErr ReadingError
+		if errors.As(err, &readingErr) {
+			readingErrs = append(readingErrs, readingErr)
+		}
+	}
+	return readingErrs
 }
@@ -0,0 +1,242 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderError(t *testing.T) {
+	labels := map[string]string{"Age": "Alter"}
+	messages := map[error]string{ErrParsingType: "ungültiger Wert"}
+
+	adapter, err := NewCSVAdapter[Person](
+		RenderErrors(func(d ErrorDetails) string {
+			if d.Reading == nil {
+				return messages[d.Sentinel]
+			}
+			return fmt.Sprintf("Zeile %d, %s: %s", d.Reading.Line, labels[d.Reading.Field], messages[d.Sentinel])
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := `name,age,email
+John Doe,thirty,` + fakemail + `
+`
+	people, err := adapter.FromCSV(bytes.NewReader([]byte(csvData)))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for _, err := range people {
+		if err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+		expected := "Zeile 1, Alter: ungültiger Wert"
+		if msg := adapter.RenderError(err); msg != expected {
+			t.Errorf("expected %q, got %q", expected, msg)
+		}
+	}
+
+	if msg := adapter.RenderError(nil); msg != "" {
+		t.Errorf("expected empty message, got %q", msg)
+	}
+}
+
+func TestRenderErrorWithoutRenderer(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	err = errors.Join(ErrFieldNotFound, fmt.Errorf("field email"))
+	if msg := adapter.RenderError(err); msg != err.Error() {
+		t.Errorf("expected %q, got %q", err.Error(), msg)
+	}
+}
+
+func TestNewErrorDetails(t *testing.T) {
+	err := errors.Join(
+		ErrProcessingCSVLines,
+		ReadingError{Line: 3, Field: "Name", FieldAlias: "name"},
+		ErrEmptyValue,
+	)
+	details := NewErrorDetails(err)
+	if details.Sentinel != ErrEmptyValue {
+		t.Errorf("expected ErrEmptyValue, got %v", details.Sentinel)
+	}
+	if details.Reading == nil || details.Reading.Line != 3 {
+		t.Errorf("expected reading error at line 3, got %+v", details.Reading)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	adapter, err := NewCSVAdapter[PersonWithManyTypes]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		csvData  string
+		expected Code
+	}{
+		{"empty value", "name,age,email,some_float,some_bool,some_ptr\nJohn,,,1.5,true,x\n", CodeEmptyValue},
+		{"parse int", "name,age,email,some_float,some_bool,some_ptr\nJohn,x,,1.5,true,x\n", CodeParseInt},
+		{"parse float", "name,age,email,some_float,some_bool,some_ptr\nJohn,1,,x,true,x\n", CodeParseFloat},
+		{"parse bool", "name,age,email,some_float,some_bool,some_ptr\nJohn,1,,1.5,x,x\n", CodeParseBool},
+		{"malformed row", "name,age,email,some_float,some_bool,some_ptr\nJohn,1\n", CodeMalformedRow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := adapter.FromCSV(bytes.NewReader([]byte(tt.csvData)))
+			if err != nil {
+				t.Fatalf("failed to read CSV: %v", err)
+			}
+			for _, err := range rows {
+				if code := ErrorCode(err); code != tt.expected {
+					t.Errorf("expected %s, got %s (%v)", tt.expected, code, err)
+				}
+			}
+		})
+	}
+
+	t.Run("missing field", func(t *testing.T) {
+		_, err := adapter.FromCSV(bytes.NewReader([]byte("name\nJohn\n")))
+		if code := ErrorCode(err); code != CodeFieldMissing {
+			t.Errorf("expected %s, got %s", CodeFieldMissing, code)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if code := ErrorCode(errors.New("foo")); code != CodeUnknown {
+			t.Errorf("expected %s, got %s", CodeUnknown, code)
+		}
+		if code := ErrorCode(nil); code != "" {
+			t.Errorf("expected empty code, got %s", code)
+		}
+	})
+}
+
+func TestReadingErrorContext(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	rows, err := adapter.FromCSV(strings.NewReader("email,name,age\n" + fakemail + ",John Doe,thirty\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for _, err := range rows {
+		readingErr, ok := AsReadingError(err)
+		if !ok {
+			t.Fatalf("expected a reading error, got %v", err)
+		}
+		if readingErr.Column != 3 || readingErr.Value != "thirty" || readingErr.FieldAlias != "age" {
+			t.Errorf("expected column 3 with value thirty, got %+v", readingErr)
+		}
+		if !errors.Is(readingErr, strconv.ErrSyntax) {
+			t.Errorf("expected the cause to be strconv.ErrSyntax, got %v", readingErr.Err)
+		}
+		if !slices.Equal(readingErr.Record, []string{fakemail, name, "thirty"}) {
+			t.Errorf("expected the raw record, got %v", readingErr.Record)
+		}
+		expected := `error reading field Age (age) at line 1, column 3, value "thirty"`
+		if readingErr.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, readingErr.Error())
+		}
+	}
+
+	if _, ok := AsReadingError(ErrEmptyValue); ok {
+		t.Errorf("expected no reading error")
+	}
+}
+
+func TestReadingErrorRecordIsCopied(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](ReuseRecord(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people, err := adapter.UnmarshalAllLenient(strings.NewReader("name,age,email\nJohn Doe,x," + fakemail + "\nJane Smith,25," + otherfakemail + "\n"))
+	if len(people) != 1 {
+		t.Errorf("expected 1 person, got %+v", people)
+	}
+	readingErr, ok := AsReadingError(err)
+	if !ok {
+		t.Fatalf("expected a reading error, got %v", err)
+	}
+	if !slices.Equal(readingErr.Record, []string{name, "x", fakemail}) {
+		t.Errorf("expected the raw record, got %v", readingErr.Record)
+	}
+}
+
+func TestSentinelCodes(t *testing.T) {
+	errs := map[string]error{
+		"ErrUnsupportedTag":      ErrUnsupportedTag,
+		"ErrInvalidTag":          ErrInvalidTag,
+		"ErrorNotStruct":         ErrorNotStruct,
+		"ErrReadingCSV":          ErrReadingCSV,
+		"ErrReadingCSVLines":     ErrReadingCSVLines,
+		"ErrProcessingCSVLines":  ErrProcessingCSVLines,
+		"ErrFieldNotFound":       ErrFieldNotFound,
+		"ErrUnprocessableType":   ErrUnprocessableType,
+		"ErrParsingType":         ErrParsingType,
+		"ErrEmptyValue":          ErrEmptyValue,
+		"ErrAliasNotFound":       ErrAliasNotFound,
+		"ErrWrongNumberOfFields": ErrWrongNumberOfFields,
+		"ErrInvalidConfig":       ErrInvalidConfig,
+		"ErrUnknownColumn":       ErrUnknownColumn,
+		"ErrDuplicateColumn":     ErrDuplicateColumn,
+		"ErrCharset":             ErrCharset,
+		"ErrClosed":              ErrClosed,
+		"ErrTooManyErrors":       ErrTooManyErrors,
+		"ErrValidation":          ErrValidation,
+		"ErrConstraintViolation": ErrConstraintViolation,
+		"ErrNilRecord":           ErrNilRecord,
+		"ErrUnquotableField":     ErrUnquotableField,
+		"ErrTransform":           ErrTransform,
+		"ErrHeaderMismatch":      ErrHeaderMismatch,
+		"ErrDuplicateRow":        ErrDuplicateRow,
+		"ErrDuplicateValue":      ErrDuplicateValue,
+		"ErrUnknownVariant":      ErrUnknownVariant,
+	}
+
+	// every exported error variable of the package must be in the table
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to parse package: %v", err)
+	}
+	for _, file := range pkgs["csvadapter"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if _, ok := errs[name.Name]; strings.HasPrefix(name.Name, "Err") && !ok {
+						t.Errorf("%s is missing from the table", name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	for name, err := range errs {
+		if code := ErrorCode(err); code == CodeUnknown {
+			t.Errorf("%s: expected a code, got %s", name, code)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// namedFile is a file of a fs.FS named as in the fs.FS, for the source_file metadata field
+type namedFile struct {
+	fs.File
+	name string
+}
+
+func (f namedFile) Name() string {
+	return f.name
+}
+
+// FromFile reads the csv file name of fsys (e.g. os.DirFS or an embed.FS)
+//
+// the file is closed once the returned sequence is exhausted or the loop over
+// it is stopped, so it must be ranged over. The source_file metadata field is
+// name.
+func (c *CSVAdapter[T]) FromFile(fsys fs.FS, name string) (iter.Seq2[T, error], error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, errors.Join(ErrReadingCSV, err)
+	}
+	rows, err := c.FromCSV(namedFile{file, name}, CloseReader(true))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return rows, nil
+}
+
+// FromFiles reads the csv files names of fsys one after the other
+//
+// every file has its own header, and the source_file metadata field and the
+// lines of the errors are the ones of the file of the row. The options
+// checking rows against each other (e.g. Offset, Limit, DedupeBy) apply to each
+// file separately. The files are opened one at a time as the sequence is
+// ranged over, and an error opening a file or reading its header ends it.
+func (c *CSVAdapter[T]) FromFiles(fsys fs.FS, names ...string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var TEmpty T
+		for _, name := range names {
+			rows, err := c.FromFile(fsys, name)
+			if err != nil {
+				yield(TEmpty, errors.Join(err, fmt.Errorf("file %s", name)))
+				return
+			}
+			for item, err := range rows {
+				if !yield(item, err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FromGlob reads the csv files of fsys matching pattern (see fs.Glob) with FromFiles
+//
+// the files are read in lexical order. It returns an error if pattern is malformed.
+func (c *CSVAdapter[T]) FromGlob(fsys fs.FS, pattern string) (iter.Seq2[T, error], error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	return c.FromFiles(fsys, names...), nil
+}
+
+// ToFile writes structs to the csv file at path, replacing its content
+//
+// with the AtomicWrite option the records are written to a temporary file that
+// is renamed over path once complete, otherwise path is truncated and written
+// in place. The renamed file keeps the mode of an existing path, new files
+// are created with mode 0644.
+func (c *CSVAdapter[T]) ToFile(path string, data iter.Seq[T]) error {
+	if !c.options.atomicWrite {
+		file, err := os.Create(path)
+		if err != nil {
+			return errors.Join(ErrReadingCSV, err)
+		}
+		return errors.Join(c.ToCSV(file, data), file.Close())
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	if err := c.ToCSV(file, data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	// the temporary file replaces the target, so it takes its mode
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	err = errors.Join(file.Chmod(mode), file.Sync())
+	err = errors.Join(err, file.Close())
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return errors.Join(ErrReadingCSV, err)
+	}
+	return nil
+}
+
+// AppendToFile appends structs to the csv file at path
+//
+// the file is created with a header if it does not exist or is empty. Otherwise
+// its header must match the columns of the adapter, in the same order, and the
+// records are appended without writing the header (or a BOM) again.
+func (c *CSVAdapter[T]) AppendToFile(path string, data iter.Seq[T]) (err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	if info.Size() == 0 {
+		return c.ToCSV(file, data)
+	}
+
+	restColumns, err := c.checkFileHeader(io.NewSectionReader(file, 0, info.Size()))
+	if err != nil {
+		return err
+	}
+	// terminate the last record if the file does not end with a newline
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return errors.Join(ErrReadingCSV, err)
+	}
+	if last[0] != '\n' && c.options.compression == CompressionNone {
+		lineEnd := "\n"
+		if c.options.useCRLF {
+			lineEnd = "\r\n"
+		}
+		if _, err := file.WriteString(lineEnd); err != nil {
+			return errors.Join(ErrReadingCSV, err)
+		}
+	}
+
+	// the header and the BOM are already in the file
+	options := *c.options
+	options.writeBOM = false
+	appender := *c
+	appender.options = &options
+	encoder, err := appender.NewEncoder(file)
+	if err != nil {
+		return err
+	}
+	encoder.headerWritten = true
+	encoder.restColumns = restColumns
+	return encodeAll(encoder, data)
+}
+
+// checkFileHeader checks that the header of the csv file read by reader
+// matches the columns of the adapter and returns the columns of the rest field
+func (c *CSVAdapter[T]) checkFileHeader(reader io.Reader) ([]string, error) {
+	// positional adapters and adapters without header write no header
+	if c.positional || !c.options.writeHeader {
+		return nil, nil
+	}
+	header, err := c.readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	expected := c.Header()
+	if name := c.options.rowHashColumn; name != "" {
+		// the row hash column is written after the columns of the rest field
+		if len(header) == 0 || !c.isRowHashColumn(header[len(header)-1]) {
+			return nil, errors.Join(ErrHeaderMismatch, fmt.Errorf("expected %q as last column, got %q", name, header))
+		}
+		header = header[:len(header)-1]
+	}
+	columns := header
+	if c.restField != nil && len(header) > len(expected) {
+		columns = header[:len(expected)]
+	}
+	matches := slices.EqualFunc(columns, expected, func(column, alias string) bool {
+		return c.options.headerKey(column) == c.options.headerKey(alias)
+	})
+	if !matches {
+		return nil, errors.Join(ErrHeaderMismatch, fmt.Errorf("expected %q, got %q", expected, header))
+	}
+	return header[len(columns):], nil
+}
@@ -0,0 +1,253 @@
+package csvadapter
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAppendToFile(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	if err := adapter.AppendToFile(path, slices.Values([]Person{{name, age, fakemail}})); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	if err := adapter.AppendToFile(path, slices.Values([]Person{{othername, otherage, otherfakemail}})); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	expected := "name,age,email\n" + name + ",30," + fakemail + "\n" + othername + ",25," + otherfakemail + "\n"
+	if string(content) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestAppendToFileMissingNewline(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	if err := os.WriteFile(path, []byte("name,age,email"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := adapter.AppendToFile(path, slices.Values([]Person{{name, age, fakemail}})); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	expected := "name,age,email\n" + name + ",30," + fakemail + "\n"
+	if string(content) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestAppendToFileHeaderMismatch(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	if err := os.WriteFile(path, []byte("name,email,age\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	err = adapter.AppendToFile(path, slices.Values([]Person{{name, age, fakemail}}))
+	if !errors.Is(err, ErrHeaderMismatch) {
+		t.Fatalf("expected ErrHeaderMismatch, got %v", err)
+	}
+	if code := ErrorCode(err); code != CodeHeaderMismatch {
+		t.Fatalf("expected code %s, got %s", CodeHeaderMismatch, code)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "name,email,age\n" {
+		t.Fatalf("file was modified: %q", string(content))
+	}
+}
+
+func TestToFileFromFile(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		adapter, err := NewCSVAdapter[Person](AtomicWrite(atomic))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+
+		dir := t.TempDir()
+		people := []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+		if err := adapter.ToFile(filepath.Join(dir, "people.csv"), slices.Values(people)); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		rows, err := adapter.FromFile(os.DirFS(dir), "people.csv")
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		var read []Person
+		for person, err := range rows {
+			if err != nil {
+				t.Fatalf("failed to read person: %v", err)
+			}
+			read = append(read, person)
+		}
+		if !slices.Equal(read, people) {
+			t.Fatalf("expected %v, got %v", people, read)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("failed to read dir: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected only the csv file, got %d entries", len(entries))
+		}
+	}
+}
+
+func TestToFileAtomicError(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](AtomicWrite(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "people.csv")
+	if err := os.WriteFile(path, []byte("name,age,email\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	// empty names are rejected, the destination must be kept as is
+	err = adapter.ToFile(path, slices.Values([]Person{{"", age, fakemail}}))
+	if !errors.Is(err, ErrEmptyValue) {
+		t.Fatalf("expected ErrEmptyValue, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "name,age,email\n" {
+		t.Fatalf("file was modified: %q", string(content))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected the temporary file to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestFromFileMissing(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	if _, err := adapter.FromFile(os.DirFS(t.TempDir()), "missing.csv"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestFromFiles(t *testing.T) {
+	type PersonWithSource struct {
+		Name   string `csva:"name"`
+		Age    int    `csva:"age"`
+		Source string `csva:"-,source_file"`
+	}
+	adapter, err := NewCSVAdapter[PersonWithSource]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"a.csv":   {Data: []byte("name,age\nJohn,30\n")},
+		"b.csv":   {Data: []byte("age,name\n25,Jane\nx,Bad\n")},
+		"c.txt":   {Data: []byte("not,a\ncsv,file\n")},
+		"bad.csv": {Data: []byte("email\n")},
+	}
+	rows, err := adapter.FromGlob(fsys, "[ab].csv")
+	if err != nil {
+		t.Fatalf("failed to glob files: %v", err)
+	}
+	var read []PersonWithSource
+	var readingErr ReadingError
+	for person, err := range rows {
+		if err != nil {
+			if !errors.As(err, &readingErr) {
+				t.Fatalf("expected a ReadingError, got %v", err)
+			}
+			continue
+		}
+		read = append(read, person)
+	}
+	expected := []PersonWithSource{{"John", 30, "a.csv"}, {"Jane", 25, "b.csv"}}
+	if !slices.Equal(read, expected) {
+		t.Errorf("expected %+v, got %+v", expected, read)
+	}
+	// the lines are counted in the file of the row
+	if readingErr.Line != 2 {
+		t.Errorf("expected the error on line 2, got %d", readingErr.Line)
+	}
+
+	// the header of a file ends the sequence
+	var errs []error
+	for _, err := range adapter.FromFiles(fsys, "a.csv", "bad.csv", "b.csv") {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrFieldNotFound) {
+		t.Errorf("expected a single ErrFieldNotFound, got %v", errs)
+	}
+
+	if _, err := adapter.FromGlob(fsys, "[a"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestToFileAtomicMode(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](AtomicWrite(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	dir := t.TempDir()
+	people := slices.Values([]Person{{name, age, fakemail}})
+	for file, mode := range map[string]fs.FileMode{"new.csv": 0o644, "private.csv": 0o600} {
+		path := filepath.Join(dir, file)
+		if mode != 0o644 {
+			if err := os.WriteFile(path, nil, mode); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+		}
+		if err := adapter.ToFile(path, people); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat file: %v", err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("%s: expected mode %v, got %v", file, mode, info.Mode().Perm())
+		}
+	}
+}
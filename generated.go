@@ -0,0 +1,71 @@
+package csvadapter
+
+// FieldUnmarshaler is implemented by pointers to structs parsing their own fields
+// without reflection, e.g. with the code generated by cmd/csvadapter-gen
+//
+// index is the index of the field in the struct. UnmarshalCSVField reports whether
+// it handled the field, the adapter falls back to reflection otherwise. Empty cells,
+// defaults, nulls and constraints are still handled by the adapter, and fields
+// formatted by converters or tag options and adapter options changing the
+// formatting (e.g. base or BoolValues) are never passed to it.
+type FieldUnmarshaler interface {
+	UnmarshalCSVField(index int, value string) (bool, error)
+}
+
+// FieldMarshaler is implemented by structs formatting their own fields
+// without reflection, e.g. with the code generated by cmd/csvadapter-gen
+//
+// index is the index of the field in the struct. MarshalCSVField reports whether
+// it handled the field, the adapter falls back to reflection otherwise. Like for
+// FieldUnmarshaler, only fields with the default formatting are passed to it.
+type FieldMarshaler interface {
+	MarshalCSVField(index int) (string, bool, error)
+}
+
+// RecordUnmarshaler is implemented by pointers to structs parsing whole records
+// without reflection, e.g. with the code generated by cmd/csvadapter-gen
+//
+// columns holds the column of each field of the struct by index, -1 for the
+// fields the adapter does not read. UnmarshalCSVRecord reports whether it parsed
+// the record; if not (e.g. for an empty or invalid cell), the adapter decodes the
+// record with reflection, so errors are reported like for other structs. It is
+// only used if all the fields of the adapter have the default formatting and no
+// tag option handling empty cells or constraints (e.g. trim, default or min).
+type RecordUnmarshaler interface {
+	UnmarshalCSVRecord(record []string, columns []int) bool
+}
+
+// RecordMarshaler is implemented by structs formatting whole records without
+// reflection, e.g. with the code generated by cmd/csvadapter-gen
+//
+// columns holds the position of each field of the struct in record by index,
+// -1 for the fields the adapter does not write. MarshalCSVRecord reports whether
+// it formatted the record, the adapter falls back to reflection otherwise. Like
+// for RecordUnmarshaler, it is only used for structs with the default formatting.
+type RecordMarshaler interface {
+	MarshalCSVRecord(record []string, columns []int) bool
+}
+
+// recordColumns returns the columns passed to RecordUnmarshaler and RecordMarshaler
+// for the columns of the fields of the adapter, or nil if they cannot handle its rows
+func (c *CSVAdapter[T]) recordColumns(columns []int) []int {
+	if len(c.metaFields) > 0 || c.restField != nil {
+		return nil
+	}
+	byIndex := make([]int, c.structType.NumField())
+	for i := range byIndex {
+		byIndex[i] = -1
+	}
+	for i, f := range c.fields {
+		if !f.generated || len(f.index) != 1 || f.trim || c.null(&f) != "" || len(f.empties) > 0 ||
+			f.hasDefault || f.min != nil || f.max != nil || f.pattern != nil {
+			return nil
+		}
+		// missing columns are handled by the adapter
+		if columns[i] < 0 {
+			return nil
+		}
+		byIndex[f.index[0]] = columns[i]
+	}
+	return byIndex
+}
@@ -0,0 +1,160 @@
+package csvadapter
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// generatedPerson implements the methods csvadapter-gen generates,
+// counting the fields they handle
+type generatedPerson struct {
+	Name  string `csva:"name"`
+	Age   int    `csva:"age"`
+	Flags int    `csva:"flags,base=16"`
+	calls int    `csva:"-"`
+}
+
+func (x *generatedPerson) UnmarshalCSVField(index int, value string) (bool, error) {
+	x.calls++
+	switch index {
+	case 0:
+		x.Name = value
+		return true, nil
+	case 1:
+		v, err := strconv.ParseInt(value, 10, 0)
+		if err != nil {
+			return true, err
+		}
+		x.Age = int(v)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (x generatedPerson) MarshalCSVField(index int) (string, bool, error) {
+	switch index {
+	case 0:
+		return strings.ToUpper(x.Name), true, nil
+	}
+	return "", false, nil
+}
+
+func TestGeneratedFields(t *testing.T) {
+	adapter, err := NewCSVAdapter[generatedPerson]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err := adapter.UnmarshalAll(strings.NewReader("name,age,flags\nJohn Doe,30,ff\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	// the flags field has a base, so it is parsed with reflection
+	if expected := (generatedPerson{name, age, 255, 2}); people[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, people[0])
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("name,age,flags\nJohn Doe,old,ff\n")); ErrorCode(err) != CodeParseInt {
+		t.Errorf("expected %s, got %v", CodeParseInt, err)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, people); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if expected := "name,age,flags\nJOHN DOE,30,ff\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
+
+// generatedRecord implements the record methods csvadapter-gen generates,
+// counting the records they parse
+type generatedRecord struct {
+	Name    string `csva:"name"`
+	Age     int    `csva:"age"`
+	records int    `csva:"-"`
+}
+
+func (x *generatedRecord) UnmarshalCSVRecord(record []string, columns []int) bool {
+	if len(columns) != 3 {
+		return false
+	}
+	if c := columns[0]; c >= 0 {
+		if c >= len(record) || record[c] == "" {
+			return false
+		}
+		x.Name = record[c]
+	}
+	if c := columns[1]; c >= 0 {
+		if c >= len(record) || record[c] == "" {
+			return false
+		}
+		v, err := strconv.ParseInt(record[c], 10, 0)
+		if err != nil {
+			return false
+		}
+		x.Age = int(v)
+	}
+	x.records++
+	return true
+}
+
+func (x generatedRecord) MarshalCSVRecord(record []string, columns []int) bool {
+	if len(columns) != 3 {
+		return false
+	}
+	if c := columns[0]; c >= 0 {
+		if x.Name == "" {
+			return false
+		}
+		record[c] = strings.ToUpper(x.Name)
+	}
+	if c := columns[1]; c >= 0 {
+		record[c] = strconv.FormatInt(int64(x.Age), 10)
+	}
+	return true
+}
+
+func TestGeneratedRecords(t *testing.T) {
+	adapter, err := NewCSVAdapter[generatedRecord]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := adapter.UnmarshalAll(strings.NewReader("age,name\n30,John Doe\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if expected := (generatedRecord{name, age, 1}); rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, rows[0])
+	}
+
+	// invalid cells are decoded again with reflection, reporting the error
+	if _, err := adapter.UnmarshalAll(strings.NewReader("name,age\nJohn Doe,old\n")); ErrorCode(err) != CodeParseInt {
+		t.Errorf("expected %s, got %v", CodeParseInt, err)
+	}
+	if _, err := adapter.UnmarshalAll(strings.NewReader("name,age\n,30\n")); ErrorCode(err) != CodeEmptyValue {
+		t.Errorf("expected %s, got %v", CodeEmptyValue, err)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, rows); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if expected := "name,age\nJOHN DOE,30\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	// trimmed cells are not passed to the record methods
+	trimmed, err := NewCSVAdapter[generatedRecord](TrimSpace(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err = trimmed.UnmarshalAll(strings.NewReader("name,age\nJohn Doe, 30\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if expected := (generatedRecord{name, age, 0}); rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, rows[0])
+	}
+}
@@ -0,0 +1,55 @@
+package csvadapter
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+)
+
+// ServeCSV writes structs to an http response as a csv file
+//
+// the Content-Type header is set, and the Content-Disposition header too if
+// filename is not empty, so browsers download the file under that name. The
+// records are streamed, so an error after the first record can only be
+// reported to the caller, not in the status code.
+func (c *CSVAdapter[T]) ServeCSV(w http.ResponseWriter, data iter.Seq[T], filename string) error {
+	contentType := "text/csv; charset=utf-8"
+	if c.options.compression == CompressionGzip {
+		contentType = "application/gzip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	return c.ToCSV(w, data)
+}
+
+// FromRequest reads the csv file uploaded with an http request
+//
+// multipart/form-data requests are read from their first file part, other
+// requests from their body. The upload is streamed, so the returned sequence
+// must be ranged over before the handler returns.
+func (c *CSVAdapter[T]) FromRequest(r *http.Request) (iter.Seq2[T, error], error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return c.FromCSV(r.Body)
+	}
+	parts, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.Join(ErrReadingCSV, err)
+	}
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return nil, errors.Join(ErrReadingCSV, http.ErrMissingFile)
+		}
+		if err != nil {
+			return nil, errors.Join(ErrReadingCSV, err)
+		}
+		if part.FileName() != "" {
+			return c.FromCSV(part)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestServeCSV(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := adapter.ServeCSV(recorder, slices.Values([]Person{{name, age, fakemail}}), "people.csv"); err != nil {
+		t.Fatalf("failed to serve csv: %v", err)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != "attachment; filename=people.csv" {
+		t.Fatalf("unexpected content disposition %q", disposition)
+	}
+	expected := "name,age,email\n" + name + ",30," + fakemail + "\n"
+	if recorder.Body.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, recorder.Body.String())
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	csvData := "name,age,email\n" + name + ",30," + fakemail + "\n"
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	if err := form.WriteField("comment", "upload"); err != nil {
+		t.Fatalf("failed to write field: %v", err)
+	}
+	file, err := form.CreateFormFile("file", "people.csv")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	file.Write([]byte(csvData))
+	form.Close()
+
+	multipartRequest := httptest.NewRequest(http.MethodPost, "/upload", body)
+	multipartRequest.Header.Set("Content-Type", form.FormDataContentType())
+	rawRequest := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(csvData))
+	rawRequest.Header.Set("Content-Type", "text/csv")
+
+	for _, request := range []*http.Request{multipartRequest, rawRequest} {
+		rows, err := adapter.FromRequest(request)
+		if err != nil {
+			t.Fatalf("failed to read request: %v", err)
+		}
+		var people []Person
+		for person, err := range rows {
+			if err != nil {
+				t.Fatalf("failed to read person: %v", err)
+			}
+			people = append(people, person)
+		}
+		if !slices.Equal(people, []Person{{name, age, fakemail}}) {
+			t.Fatalf("unexpected people %v", people)
+		}
+	}
+}
+
+func TestFromRequestMissingFile(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("comment", "upload")
+	form.Close()
+	request := httptest.NewRequest(http.MethodPost, "/upload", body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+
+	if _, err := adapter.FromRequest(request); !errors.Is(err, http.ErrMissingFile) {
+		t.Fatalf("expected http.ErrMissingFile, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package csvadapter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultSampleRows is the number of rows InferSchema reads if none is given
+const defaultSampleRows = 1000
+
+// Schema is a list of columns, e.g. inferred by InferSchema for NewDynamicAdapter
+type Schema []Column
+
+// inferredType is a type InferSchema can detect, in order of preference
+type inferredType struct {
+	typ     reflect.Type
+	options string // tag options of the columns of the type
+	matches func(value string) bool
+}
+
+var inferredTypes = []inferredType{
+	{reflect.TypeFor[bool](), "", func(value string) bool {
+		switch value {
+		case "true", "True", "TRUE", "false", "False", "FALSE":
+			return true
+		}
+		return false
+	}},
+	{reflect.TypeFor[int](), "", func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 0)
+		return err == nil
+	}},
+	{reflect.TypeFor[float64](), "", func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}},
+	{timeType, "", func(value string) bool {
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	}},
+	{timeType, _TAG_FORMAT + "=DateTime", func(value string) bool {
+		_, err := time.Parse(time.DateTime, value)
+		return err == nil
+	}},
+	{timeType, _TAG_FORMAT + "=DateOnly", func(value string) bool {
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	}},
+}
+
+// InferSchema guesses the columns of a csv file from its header and its first
+// sampleRows rows (1000 if sampleRows is not positive)
+//
+// the type of a column is the first of bool, int, float64 and time.Time (RFC 3339,
+// DateTime or DateOnly) all its cells can be parsed as, string otherwise. Columns
+// with empty cells are omitempty. r is opened like by FromCSV, so e.g. Comma,
+// WithCompression and Charset apply; with the NoHeader option the columns are
+// named column1, column2...
+func InferSchema(r io.Reader, sampleRows int, options ...csvAdapterOption) (Schema, error) {
+	o := newCSVAdapterOptions()
+	for _, option := range options {
+		option(o)
+	}
+	if sampleRows <= 0 {
+		sampleRows = defaultSampleRows
+	}
+	input, _, err := o.openReader(r)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(input)
+	o.applyReader(reader)
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	if !o.noHeader {
+		if header, err = reader.Read(); err != nil {
+			return nil, errors.Join(ErrReadingCSVLines, err)
+		}
+	}
+	var candidates [][]bool // types each column can still have
+	var omitEmpty, filled []bool
+	for rows := 0; rows < sampleRows; rows++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Join(ErrReadingCSVLines, err)
+		}
+		for len(candidates) < max(len(record), len(header)) {
+			possible := make([]bool, len(inferredTypes))
+			for i := range possible {
+				possible[i] = true
+			}
+			candidates = append(candidates, possible)
+			omitEmpty = append(omitEmpty, rows > 0) // missing in the previous rows
+			filled = append(filled, false)
+		}
+		for i := range candidates {
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			if o.trimSpace {
+				value = strings.TrimSpace(value)
+			}
+			if value == "" {
+				omitEmpty[i] = true
+				continue
+			}
+			filled[i] = true
+			for j, t := range inferredTypes {
+				candidates[i][j] = candidates[i][j] && t.matches(value)
+			}
+		}
+	}
+
+	schema := make(Schema, max(len(header), len(candidates)))
+	for i := range schema {
+		column := Column{Type: reflect.TypeFor[string]()}
+		if i < len(header) {
+			column.Name = header[i]
+		} else {
+			column.Name = "column" + strconv.Itoa(i+1)
+		}
+		if i < len(candidates) && filled[i] {
+			for j, t := range inferredTypes {
+				if candidates[i][j] {
+					column.Type, column.Options = t.typ, t.options
+					break
+				}
+			}
+		}
+		if i >= len(candidates) || omitEmpty[i] {
+			column.Options = strings.TrimSuffix(_TAG_OMITEMPTY+","+column.Options, ",")
+		}
+		schema[i] = column
+	}
+	return schema, nil
+}
+
+// GoStruct returns the source of a Go file of package pkgName declaring a
+// struct typeName with a field per column, e.g. to bootstrap the struct of a
+// new file from the schema inferred by InferSchema
+//
+// the field names are the column names in CamelCase, and only the time package
+// is imported for the types of the columns. Every field has the column name as
+// an explicit alias, and duplicate field names get a numeric suffix. Columns
+// whose name cannot be an alias in a tag (i.e. containing a comma, an equal
+// sign or a quote) are rejected with ErrInvalidTag.
+func (s Schema) GoStruct(pkgName, typeName string) ([]byte, error) {
+	if slices.ContainsFunc(s, func(c Column) bool { return c.Type == nil }) {
+		return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("columns must have a type"))
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	if slices.ContainsFunc(s, func(c Column) bool { return isTimeField(c.Type) }) {
+		buf.WriteString("import \"time\"\n\n")
+	}
+	fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+	// the suffixed names of duplicates must not collide with the name of another column
+	reserved := make(map[string]bool, len(s))
+	for _, column := range s {
+		reserved[goFieldName(column.Name)] = true
+	}
+	used := make(map[string]bool, len(s))
+	for _, column := range s {
+		if strings.ContainsAny(column.Name, ",=\"`") {
+			return nil, errors.Join(ErrInvalidTag, fmt.Errorf("column %q cannot be an alias", column.Name))
+		}
+		base := goFieldName(column.Name)
+		name := base
+		for n := 2; used[name] || name != base && reserved[name]; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		used[name] = true
+		tag := column.Name
+		if tag == "" || tag == _TAG_SKIP {
+			// the first part would be no alias or skip the field
+			tag = _TAG_ALIAS + "=" + tag
+		}
+		if column.Options != "" {
+			tag += "," + column.Options
+		}
+		fmt.Fprintf(&buf, "\t%s %s `%s:%q`\n", name, column.Type, _TAG, tag)
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// goFieldName converts a column name into an exported Go identifier in CamelCase
+func goFieldName(column string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range column {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if name.Len() == 0 && unicode.IsDigit(r) {
+			name.WriteString("Column")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	if name.Len() == 0 {
+		return "Column"
+	}
+	return name.String()
+}
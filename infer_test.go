@@ -0,0 +1,138 @@
+package csvadapter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInferSchema(t *testing.T) {
+	input := "id;name;score;active;born;seen;note\n" +
+		"1;John Doe;1.5;true;1990-01-02;2024-01-02T10:00:00Z;\n" +
+		"2;Jane Smith;2;FALSE;1991-03-04;2024-01-03T11:00:00+02:00;\n"
+	schema, err := InferSchema(strings.NewReader(input), 0, Comma(';'))
+	if err != nil {
+		t.Fatalf("failed to infer schema: %v", err)
+	}
+	expected := Schema{
+		{Name: "id", Type: reflect.TypeFor[int]()},
+		{Name: "name", Type: reflect.TypeFor[string]()},
+		{Name: "score", Type: reflect.TypeFor[float64]()},
+		{Name: "active", Type: reflect.TypeFor[bool]()},
+		{Name: "born", Type: reflect.TypeFor[time.Time](), Options: "format=DateOnly"},
+		{Name: "seen", Type: reflect.TypeFor[time.Time]()},
+		{Name: "note", Type: reflect.TypeFor[string](), Options: "omitempty"},
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("expected %+v, got %+v", expected, schema)
+	}
+
+	// the schema reads the file with a dynamic adapter
+	adapter, err := NewDynamicAdapter(schema, Comma(';'))
+	if err != nil {
+		t.Fatalf("failed to create dynamic adapter: %v", err)
+	}
+	rows, err := adapter.FromCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read csv: %v", err)
+		}
+		if _, ok := row["born"].(time.Time); !ok {
+			t.Errorf("expected a time, got %T", row["born"])
+		}
+	}
+
+	// only the sample is inspected
+	schema, err = InferSchema(strings.NewReader("n\n1\nx\n"), 1)
+	if err != nil {
+		t.Fatalf("failed to infer schema: %v", err)
+	}
+	if schema[0].Type != reflect.TypeFor[int]() {
+		t.Errorf("expected int, got %v", schema[0].Type)
+	}
+}
+
+func TestGoStruct(t *testing.T) {
+	schema := Schema{
+		{Name: "id", Type: reflect.TypeFor[int]()},
+		{Name: "first name", Type: reflect.TypeFor[string]()},
+		{Name: "born", Type: reflect.TypeFor[time.Time](), Options: "omitempty,format=DateOnly"},
+		{Name: "2fa", Type: reflect.TypeFor[bool]()},
+		{Name: "ID", Type: reflect.TypeFor[float64]()},
+	}
+	src, err := schema.GoStruct("people", "Person")
+	if err != nil {
+		t.Fatalf("failed to generate struct: %v", err)
+	}
+	expected := "package people\n\n" +
+		"import \"time\"\n\n" +
+		"type Person struct {\n" +
+		"\tId        int       `csva:\"id\"`\n" +
+		"\tFirstName string    `csva:\"first name\"`\n" +
+		"\tBorn      time.Time `csva:\"born,omitempty,format=DateOnly\"`\n" +
+		"\tColumn2fa bool      `csva:\"2fa\"`\n" +
+		"\tID        float64   `csva:\"ID\"`\n" +
+		"}\n"
+	if string(src) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, src)
+	}
+
+	if _, err := (Schema{{Name: "a,b", Type: reflect.TypeFor[string]()}}).GoStruct("people", "Person"); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected %v, got %v", ErrInvalidTag, err)
+	}
+}
+
+func TestGoStructColumnNames(t *testing.T) {
+	schema := Schema{
+		{Name: "-", Type: reflect.TypeFor[string]()},
+		{Name: "", Type: reflect.TypeFor[string](), Options: "omitempty"},
+		{Name: "a b", Type: reflect.TypeFor[string]()},
+		{Name: "a_b", Type: reflect.TypeFor[string]()},
+		{Name: "AB2", Type: reflect.TypeFor[string]()},
+	}
+	src, err := schema.GoStruct("rows", "Row")
+	if err != nil {
+		t.Fatalf("failed to generate struct: %v", err)
+	}
+	expected := "package rows\n\n" +
+		"type Row struct {\n" +
+		"\tColumn  string `csva:\"alias=-\"`\n" +
+		"\tColumn2 string `csva:\"alias=,omitempty\"`\n" +
+		"\tAB      string `csva:\"a b\"`\n" +
+		"\tAB3     string `csva:\"a_b\"`\n" +
+		"\tAB2     string `csva:\"AB2\"`\n" +
+		"}\n"
+	if string(src) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, src)
+	}
+
+	// the generated tags map every column
+	type Row struct {
+		Column  string `csva:"alias=-"`
+		Column2 string `csva:"alias=,omitempty"`
+		AB      string `csva:"a b"`
+		AB3     string `csva:"a_b"`
+		AB2     string `csva:"AB2"`
+	}
+	csva, err := NewCSVAdapter[Row]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := csva.FromCSV(strings.NewReader("-,,a b,a_b,AB2\n1,2,3,4,5\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read csv: %v", err)
+		}
+		if row != (Row{"1", "2", "3", "4", "5"}) {
+			t.Errorf("expected all columns, got %+v", row)
+		}
+	}
+}
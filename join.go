@@ -0,0 +1,68 @@
+package csvadapter
+
+import (
+	"io"
+	"iter"
+)
+
+// JoinMode sets which rows of the left file Join yields
+type JoinMode int
+
+const (
+	InnerJoin JoinMode = iota // only the left rows matching a right row
+	LeftJoin                  // every left row, with a zero right row if there is no match
+)
+
+// Joined is a row of the left file with its matching row of the right file
+type Joined[L, R any] struct {
+	Left    L
+	Right   R
+	Matched bool // false if the left row has no match (LeftJoin only)
+}
+
+// Join reads two csv files and yields the rows of left matched with the rows
+// of right on a key, e.g. to enrich rows with a lookup file
+//
+// right is read in memory first, left is streamed. A left row matching several
+// right rows is yielded once per match, in the order of the right file. The
+// errors of left rows are yielded, an error in right fails the join.
+func Join[L, R any](
+	left *CSVAdapter[L], leftReader io.Reader, leftKey func(L) string,
+	right *CSVAdapter[R], rightReader io.Reader, rightKey func(R) string,
+	mode JoinMode,
+) (iter.Seq2[Joined[L, R], error], error) {
+	lookup, err := right.UnmarshalAll(rightReader)
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string][]R, len(lookup))
+	for _, item := range lookup {
+		k := rightKey(item)
+		matches[k] = append(matches[k], item)
+	}
+	rows, err := left.FromCSV(leftReader)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(Joined[L, R], error) bool) {
+		for item, err := range rows {
+			if err != nil {
+				if !yield(Joined[L, R]{}, err) {
+					return
+				}
+				continue
+			}
+			found := matches[leftKey(item)]
+			if len(found) == 0 && mode == LeftJoin {
+				if !yield(Joined[L, R]{Left: item}, nil) {
+					return
+				}
+			}
+			for _, match := range found {
+				if !yield(Joined[L, R]{Left: item, Right: match, Matched: true}, nil) {
+					return
+				}
+			}
+		}
+	}, nil
+}
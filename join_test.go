@@ -0,0 +1,67 @@
+package csvadapter
+
+import (
+	"strings"
+	"testing"
+)
+
+type City struct {
+	Person string `csva:"person"`
+	City   string `csva:"city"`
+}
+
+func TestJoin(t *testing.T) {
+	people, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	cities, err := NewCSVAdapter[City]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	left := "name,age,email\n" +
+		"John Doe,30," + fakemail + "\n" +
+		"Jane Smith,25," + otherfakemail + "\n"
+	right := "person,city\n" +
+		"John Doe,Berlin\n" +
+		"John Doe,Paris\n"
+	for _, tc := range []struct {
+		mode     JoinMode
+		expected []string
+	}{
+		{InnerJoin, []string{"John Doe Berlin", "John Doe Paris"}},
+		{LeftJoin, []string{"John Doe Berlin", "John Doe Paris", "Jane Smith "}},
+	} {
+		rows, err := Join(
+			people, strings.NewReader(left), func(p Person) string { return p.Name },
+			cities, strings.NewReader(right), func(c City) string { return c.Person },
+			tc.mode,
+		)
+		if err != nil {
+			t.Fatalf("failed to join: %v", err)
+		}
+		var joined []string
+		for row, err := range rows {
+			if err != nil {
+				t.Fatalf("failed to join: %v", err)
+			}
+			if row.Matched != (row.Right.City != "") {
+				t.Errorf("unexpected match %+v", row)
+			}
+			joined = append(joined, row.Left.Name+" "+row.Right.City)
+		}
+		if strings.Join(joined, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("expected %v, got %v", tc.expected, joined)
+		}
+	}
+
+	_, err = Join(
+		people, strings.NewReader(left), func(p Person) string { return p.Name },
+		cities, strings.NewReader("person,city\nJohn Doe\n"), func(c City) string { return c.Person },
+		InnerJoin,
+	)
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
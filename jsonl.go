@@ -0,0 +1,210 @@
+package csvadapter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// jsonlWriter writes the records of an Encoder as JSON objects, one per line
+//
+// the keys are the columns of the records. Empty cells are written as null,
+// and the cells of number, bool and json fields as JSON values if they are valid.
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns func() ([]string, []bool) // columns of the records and if their cells can be written as is
+	keys    []string
+	raw     []bool
+	err     error
+}
+
+func (j *jsonlWriter) Write(record []string) error {
+	if j.err != nil {
+		return j.err
+	}
+	if j.keys == nil {
+		j.keys, j.raw = j.columns()
+	}
+	j.w.WriteByte('{')
+	first := true
+	for i, cell := range record {
+		if j.keys[i] == "" { // positions without field
+			continue
+		}
+		if !first {
+			j.w.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(j.keys[i])
+		j.w.Write(key)
+		j.w.WriteByte(':')
+		switch {
+		case cell == "":
+			j.w.WriteString("null")
+		case j.raw[i] && json.Valid([]byte(cell)):
+			j.w.WriteString(cell)
+		default:
+			value, _ := json.Marshal(cell)
+			j.w.Write(value)
+		}
+	}
+	j.w.WriteByte('}')
+	_, j.err = j.w.WriteString("\n")
+	return j.err
+}
+
+func (j *jsonlWriter) Flush() {
+	if j.err == nil {
+		j.err = j.w.Flush()
+	}
+}
+
+func (j *jsonlWriter) Error() error {
+	return j.err
+}
+
+// ToJSONL writes structs as JSON objects, one per line
+//
+// the keys are the aliases of the fields and the values are formatted like the
+// cells of ToCSV. Numbers, booleans and json fields are written as JSON values
+// (numbers in another base or with separators as strings), the other cells as
+// strings, and nil and empty values as null rather than their null and empty
+// representations. The output is compressed with the WithCompression option.
+func (c *CSVAdapter[T]) ToJSONL(writer io.Writer, data iter.Seq[T]) error {
+	output, closeOutput, err := c.options.openWriter(writer)
+	if err != nil {
+		return err
+	}
+	buffered, release := getBufferedWriter(output, c.options.writeBufferSize)
+	jsonWriter := &jsonlWriter{w: buffered}
+	encoder := c.newEncoder(jsonWriter, release, closeOutput)
+	encoder.header, encoder.nullCells = false, true
+	jsonWriter.columns = func() ([]string, []bool) { return encoder.columns(true) }
+	return encodeAll(encoder, data)
+}
+
+// FromJSONL reads structs from JSON objects, one per line
+//
+// the keys are matched with the aliases of the fields like a header, and the
+// values are parsed like cells: strings are taken as is, null is an empty cell,
+// and other values (e.g. numbers, arrays for json fields) are taken as their JSON text.
+// Rows are read like by FromCSV, except reading stops at the first malformed line.
+func (c *CSVAdapter[T]) FromJSONL(reader io.Reader) (iter.Seq2[T, error], error) {
+	input, _, err := c.options.openReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	decoder := &Decoder[T]{adapter: c}
+	if err := decoder.prepare(reader); err != nil {
+		return nil, err
+	}
+	jsonDecoder := json.NewDecoder(input)
+	return func(yield func(T, error) bool) {
+		var TEmpty T
+		options := c.options
+		for !decoder.stopped && (options.limit <= 0 || decoder.decoded < options.limit) {
+			var object map[string]json.RawMessage
+			offset := jsonDecoder.InputOffset()
+			err := jsonDecoder.Decode(&object)
+			if err == io.EOF {
+				break
+			}
+			decoder.line++
+			if decoder.discarded < options.offset {
+				decoder.discarded++
+				continue
+			}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+				// the JSON decoder cannot continue after a malformed line
+				yield(TEmpty, errors.Join(ErrReadingCSVLines, ReadingError{Line: decoder.line, Code: CodeMalformedRow, Err: err}, err))
+				return
+			}
+			r := rawRecord{offset: offset, line: decoder.line}
+			code := CodeMalformedRow // e.g. a line that is not an object
+			if err == nil {
+				r.record, err = decoder.jsonlRecord(object)
+				code = ErrorCode(err)
+			}
+			if err != nil {
+				err = errors.Join(ErrReadingCSVLines, ReadingError{Line: decoder.line, Code: code, Err: err}, err)
+			} else if keep := options.filterRecord; keep != nil && !keep(r.record) {
+				decoder.dropped++
+				continue
+			}
+			item := TEmpty
+			if err == nil {
+				item, err = decoder.decodeRow(r)
+			}
+			if item, ok, err := decoder.settle(item, err, r.line, r.record); ok && !yield(item, err) {
+				return
+			}
+		}
+		for item, ok := decoder.nextHeld(); ok && !decoder.stopped; item, ok = decoder.nextHeld() {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// jsonlRecord converts a JSON object into a record, matching its keys like a header
+func (d *Decoder[T]) jsonlRecord(object map[string]json.RawMessage) ([]string, error) {
+	c := d.adapter
+	if c.positional || c.fallback {
+		// the keys are the aliases, the records are laid out by position
+		width := 0
+		for _, f := range c.fields {
+			width = max(width, f.column+1)
+		}
+		if d.columns == nil {
+			d.columns, _, _ = c.resolveColumns(nil)
+		}
+		record := make([]string, width)
+		for _, f := range c.fields {
+			value, err := jsonlCell(object[f.alias])
+			if err != nil {
+				return nil, err
+			}
+			record[f.column] = value
+		}
+		return record, nil
+	}
+	keys := slices.Sorted(maps.Keys(object))
+	if d.columns == nil || !slices.Equal(keys, d.header) {
+		columns, restColumns, err := c.resolveColumns(keys)
+		if err != nil {
+			return nil, err
+		}
+		d.header, d.columns, d.restColumns = keys, columns, restColumns
+	}
+	record := make([]string, len(keys))
+	for i, key := range keys {
+		value, err := jsonlCell(object[key])
+		if err != nil {
+			return nil, err
+		}
+		record[i] = value
+	}
+	return record, nil
+}
+
+// jsonlCell converts a JSON value into a cell
+func jsonlCell(value json.RawMessage) (string, error) {
+	value = bytes.TrimSpace(value)
+	switch {
+	case len(value) == 0 || bytes.Equal(value, []byte("null")):
+		return "", nil
+	case value[0] == '"':
+		var str string
+		err := json.Unmarshal(value, &str)
+		return str, err
+	default:
+		return string(value), nil
+	}
+}
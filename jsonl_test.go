@@ -0,0 +1,75 @@
+package csvadapter
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONL(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, 30, fakemail}, {"Jane Smith", 25, ""}}
+	var output bytes.Buffer
+	if err := adapter.ToJSONL(&output, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write jsonl: %v", err)
+	}
+	expected := `{"name":"John Doe","age":30,"email":"` + fakemail + `"}` + "\n" +
+		`{"name":"Jane Smith","age":25,"email":null}` + "\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+
+	rows, err := adapter.FromJSONL(&output)
+	if err != nil {
+		t.Fatalf("failed to read jsonl: %v", err)
+	}
+	var read []Person
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read jsonl: %v", err)
+		}
+		read = append(read, row)
+	}
+	if !slices.Equal(read, people) {
+		t.Errorf("expected %+v, got %+v", people, read)
+	}
+
+	input := `{"name":"John Doe","age":"30"}` + "\n" +
+		`{"name":"Jane Smith","age":"x"}` + "\n" +
+		`[]` + "\n" +
+		`{"name":`
+	rows, err = adapter.FromJSONL(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to read jsonl: %v", err)
+	}
+	var codes []Code
+	for row, err := range rows {
+		if err == nil && row.Age != 30 {
+			t.Errorf("expected age 30, got %d", row.Age)
+		}
+		codes = append(codes, ErrorCode(err))
+	}
+	expectedCodes := []Code{"", CodeParseInt, CodeMalformedRow, CodeMalformedRow}
+	if !slices.Equal(codes, expectedCodes) {
+		t.Errorf("expected codes %v, got %v", expectedCodes, codes)
+	}
+}
+
+func TestToJSONLNulls(t *testing.T) {
+	adapter, err := NewCSVAdapter[NullableHex](WithDialect(PostgresDialect))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	var output bytes.Buffer
+	if err := adapter.ToJSONL(&output, slices.Values([]NullableHex{{Note: nil, Flags: 16}})); err != nil {
+		t.Fatalf("failed to write jsonl: %v", err)
+	}
+	if expected := `{"note":null,"flags":"10"}` + "\n"; output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
@@ -0,0 +1,108 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// mapRow collects all the columns of a row
+type mapRow struct {
+	Columns map[string]string `csva:",rest"`
+}
+
+// FromCSVMaps reads a csv file with a header into maps keyed by column name,
+// without defining a struct
+//
+// the options of NewCSVAdapter apply, except those describing fields.
+func FromCSVMaps(reader io.Reader, options ...csvAdapterOption) (iter.Seq2[map[string]string, error], error) {
+	adapter, err := NewCSVAdapter[mapRow](options...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := adapter.FromCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(map[string]string, error) bool) {
+		for row, err := range rows {
+			if row.Columns == nil && err == nil {
+				row.Columns = map[string]string{}
+			}
+			if !yield(row.Columns, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ToCSVMaps writes maps keyed by column name to a csv file
+//
+// columns sets the order of the columns, if it is empty the sorted keys of the
+// first map are used. Keys that are not columns are rejected with ErrUnknownColumn
+// and missing keys are written as empty cells. The cells have no type, so
+// QuoteNonNumeric quotes every cell.
+func ToCSVMaps(writer io.Writer, columns []string, data iter.Seq[map[string]string], options ...csvAdapterOption) error {
+	o := newCSVAdapterOptions()
+	for _, option := range options {
+		option(o)
+	}
+	output, closeOutput, err := o.openWriter(writer)
+	if err != nil {
+		return err
+	}
+	csvWriter, release := o.newRecordWriter(output)
+	defer release()
+
+	err = func() error {
+		headerWritten := false
+		writeHeader := func() error {
+			headerWritten = true
+			if !o.writeHeader {
+				return nil
+			}
+			return csvWriter.Write(columns)
+		}
+		line := 0
+		for row := range data {
+			line++
+			if !headerWritten {
+				if len(columns) == 0 {
+					columns = slices.Sorted(maps.Keys(row))
+				}
+				if err := writeHeader(); err != nil {
+					return errors.Join(ErrReadingCSV, err)
+				}
+			}
+			record := make([]string, len(columns))
+			for key, value := range row {
+				i := slices.Index(columns, key)
+				if i < 0 {
+					return errors.Join(ErrProcessingCSVLines, ReadingError{Line: line, Code: CodeUnknownColumn}, ErrUnknownColumn, fmt.Errorf("column %s", key))
+				}
+				record[i] = value
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return errors.Join(ErrReadingCSV, err)
+			}
+		}
+		if !headerWritten {
+			if err := writeHeader(); err != nil {
+				return errors.Join(ErrReadingCSV, err)
+			}
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return errors.Join(ErrReadingCSV, err)
+		}
+		return nil
+	}()
+	if err != nil {
+		csvWriter.Flush()
+		return errors.Join(err, csvWriter.Error(), closeOutput())
+	}
+	return closeOutput()
+}
@@ -0,0 +1,54 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVMaps(t *testing.T) {
+	data := "id;name;email\n1;John Doe;" + fakemail + "\n2;Jane Smith;\n"
+	rows, err := FromCSVMaps(strings.NewReader(data), Comma(';'))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	var got []map[string]string
+	for row, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read row: %v", err)
+		}
+		got = append(got, row)
+	}
+	expected := []map[string]string{
+		{"id": "1", "name": name, "email": fakemail},
+		{"id": "2", "name": othername, "email": ""},
+	}
+	if len(got) != 2 || !maps.Equal(got[0], expected[0]) || !maps.Equal(got[1], expected[1]) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := ToCSVMaps(writer, []string{"id", "name", "email"}, slices.Values(got), Comma(';')); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != data {
+		t.Errorf("expected %q, got %q", data, writer.String())
+	}
+
+	writer.Reset()
+	if err := ToCSVMaps(writer, nil, slices.Values(got)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	sorted := "email,id,name\n" + fakemail + ",1,John Doe\n,2,Jane Smith\n"
+	if writer.String() != sorted {
+		t.Errorf("expected %q, got %q", sorted, writer.String())
+	}
+
+	err = ToCSVMaps(&bytes.Buffer{}, []string{"id"}, slices.Values(got))
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package csvadapter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AliasStyle defines how implicit aliases are derived from field names
+type AliasStyle int
+
+const (
+	ExactName AliasStyle = iota // the field name as is, e.g. CreatedAt (default)
+	SnakeCase                   // the field name in snake_case, e.g. created_at
+	LowerCase                   // the field name in lower case, e.g. createdat
+)
+
+// alias returns the implicit alias of a field name
+func (s AliasStyle) alias(name string) string {
+	switch s {
+	case SnakeCase:
+		return snakeCase(name)
+	case LowerCase:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// snakeCase converts a name to snake_case
+//
+// words are split on case changes ("CreatedAt", "HTTPServer") and on any
+// character other than letters and digits ("First Name", "first-name").
+func snakeCase(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	var b strings.Builder
+	pendingSeparator := false
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingSeparator = b.Len() > 0
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				pendingSeparator = b.Len() > 0
+			}
+		}
+		if pendingSeparator {
+			b.WriteByte('_')
+			pendingSeparator = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// NormalizeHeader is a header normalizer matching names regardless of their
+// case, spacing and separators, e.g. "First Name", "first_name" and "FirstName"
+//
+// more info: HeaderNormalizer
+func NormalizeHeader(name string) string {
+	return snakeCase(name)
+}
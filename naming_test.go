@@ -0,0 +1,76 @@
+package csvadapter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeHeader(t *testing.T) {
+	tests := map[string]string{
+		"First Name":    "first_name",
+		"  first_name ": "first_name",
+		"FIRST-NAME":    "first_name",
+		"firstName":     "first_name",
+		"FirstName":     "first_name",
+		"HTTPServer":    "http_server",
+		"Address2City":  "address2_city",
+		"e-mail (work)": "e_mail_work",
+	}
+	for in, expected := range tests {
+		if got := NormalizeHeader(in); got != expected {
+			t.Errorf("NormalizeHeader(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestHeaderNormalizer(t *testing.T) {
+	type Contact struct {
+		FirstName string `csva:"first_name"`
+		LastName  string `csva:"LastName"`
+	}
+	adapter, err := NewCSVAdapter[Contact](HeaderNormalizer(NormalizeHeader))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("First Name, last-name \nJohn,Doe\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0] != (Contact{"John", "Doe"}) {
+		t.Errorf("expected John Doe, got %+v", got)
+	}
+}
+
+func TestImplicitAliasStyle(t *testing.T) {
+	type Event struct {
+		ID        int
+		CreatedAt string
+		UserName  string `csva:"login"`
+	}
+	adapter, err := NewCSVAdapter[Event](ImplicitAliasStyle(SnakeCase))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	writer := &strings.Builder{}
+	if err := adapter.MarshalAll(writer, []Event{{1, "today", "jdoe"}}); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "id,created_at,login\n1,today,jdoe\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	options, err := OptionsFromJSON([]byte(`{"implicit_alias_style": "lower"}`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	adapter, err = NewCSVAdapter[Event](options...)
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if _, err := adapter.UnmarshalAll(strings.NewReader("id,createdat,login\n1,today,jdoe\n")); err != nil {
+		t.Errorf("expected lower case aliases, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package csvadapter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// separatorNames are the names of separators usable in tags, where "," is not allowed
+var separatorNames = map[string]rune{
+	"comma":      ',',
+	"dot":        '.',
+	"space":      ' ',
+	"apostrophe": '\'',
+	"none":       0,
+}
+
+// parseSeparator parses the value of a decimal or thousands tag option
+func parseSeparator(value string) (rune, error) {
+	if r, ok := separatorNames[value]; ok {
+		return r, nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError || size != len(value) {
+		return 0, fmt.Errorf("invalid separator %q", value)
+	}
+	return r, nil
+}
+
+// isNumberKind reports whether values of kind k are affected by number separators
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// hasNumberSeparators reports whether the field uses other separators than Go
+func (f *field) hasNumberSeparators() bool {
+	return f.thousands != 0 || (f.decimal != 0 && f.decimal != '.')
+}
+
+// normalizeNumber removes the thousands separators of a number and replaces its decimal separator with "."
+func (f *field) normalizeNumber(value string) string {
+	if f.thousands != 0 {
+		value = strings.ReplaceAll(value, string(f.thousands), "")
+	}
+	if f.decimal != 0 && f.decimal != '.' {
+		value = strings.Replace(value, string(f.decimal), ".", 1)
+	}
+	return value
+}
+
+// localizeNumber replaces the decimal separator of a formatted number
+func (f *field) localizeNumber(value string) string {
+	if f.decimal != 0 && f.decimal != '.' {
+		return strings.Replace(value, ".", string(f.decimal), 1)
+	}
+	return value
+}
@@ -0,0 +1,49 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNumberSeparators(t *testing.T) {
+	type Invoice struct {
+		Total    float64 `csva:"total"`
+		Quantity int     `csva:"quantity"`
+		Rate     float64 `csva:"rate,thousands=none,decimal=dot"`
+		Swiss    uint    `csva:"swiss,thousands=apostrophe"`
+	}
+	adapter, err := NewCSVAdapter[Invoice](NumberSeparators('.', ','), Comma(';'))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	got, err := adapter.UnmarshalAll(strings.NewReader("total;quantity;rate;swiss\n1.234,56;1.000;0.25;1'000'000\n"))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := Invoice{1234.56, 1000, 0.25, 1000000}
+	if len(got) != 1 || got[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != "total;quantity;rate;swiss\n1234,560000;1000;0.250000;1000000\n" {
+		t.Errorf("unexpected csv %q", writer.String())
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("total;quantity;rate;swiss\n1,2,3;1;0.25;1\n")); !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+
+	type WrongSeparator struct {
+		Total float64 `csva:"total,decimal=none"`
+	}
+	if _, err := NewCSVAdapter[WrongSeparator](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package csvadapter
+
+import (
+	"io"
+	"iter"
+	"sync"
+)
+
+// decodeResult is a record decoded by a worker
+type decodeResult[T any] struct {
+	r      rawRecord
+	item   T
+	err    error
+	pooled *[]string // pooled copy of the record, released once the result is settled
+}
+
+// decodeJob is a record to decode and the channel receiving its result
+type decodeJob[T any] struct {
+	r      rawRecord
+	err    error // error of a malformed record, decoded as is
+	out    chan<- decodeResult[T]
+	pooled *[]string
+}
+
+// parallel decodes the records with a pool of workers
+//
+// records are read sequentially and decoded concurrently, the Filter and the
+// error handling options are applied to the results in the calling goroutine.
+// With the PreserveOrder option, rows are yielded in the order of the file.
+func (d *Decoder[T]) parallel(workers int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ordered := d.adapter.options.preserveOrder
+		done := make(chan struct{})
+		outs := sync.Pool{New: func() any { return make(chan decodeResult[T], 1) }}
+		jobs := make(chan decodeJob[T], workers)
+		pending := make(chan chan decodeResult[T], 2*workers) // results in the order of the file
+		results := make(chan decodeResult[T], workers)        // results in any order
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(jobs)
+			defer close(pending)
+			for {
+				r, err := d.nextRecord()
+				if err == io.EOF {
+					return
+				}
+				job := decodeJob[T]{r: r, err: err, out: results}
+				if d.adapter.options.reuseRecord {
+					// the reader overwrites the record while it is decoded
+					job.pooled = getRecord(len(r.record))
+					copy(*job.pooled, r.record)
+					job.r.record = *job.pooled
+				}
+				if ordered {
+					out := outs.Get().(chan decodeResult[T])
+					job.out = out
+					select {
+					case pending <- out:
+					case <-done:
+						return
+					}
+				}
+				select {
+				case jobs <- job:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		var workersWg sync.WaitGroup
+		for range workers {
+			workersWg.Add(1)
+			go func() {
+				defer workersWg.Done()
+				for job := range jobs {
+					result := decodeResult[T]{r: job.r, err: job.err, pooled: job.pooled}
+					if result.err == nil {
+						result.item, result.err = d.decodeRow(job.r)
+					}
+					select {
+					case job.out <- result:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			workersWg.Wait()
+			close(results)
+		}()
+		defer func() {
+			close(done)
+			wg.Wait()
+			workersWg.Wait()
+		}()
+
+		next := func() (decodeResult[T], bool) {
+			if !ordered {
+				result, ok := <-results
+				return result, ok
+			}
+			out, ok := <-pending
+			if !ok {
+				return decodeResult[T]{}, false
+			}
+			result := <-out
+			outs.Put(out)
+			return result, true
+		}
+		limit := d.adapter.options.limit
+		for !d.stopped && (limit <= 0 || d.decoded < limit) {
+			result, ok := next()
+			if !ok {
+				break
+			}
+			item, ok, err := d.settle(result.item, result.err, result.r.line, result.r.record)
+			if result.pooled != nil {
+				putRecord(result.pooled)
+			}
+			if ok && !yield(item, err) {
+				return
+			}
+		}
+		for item, ok := d.nextHeld(); ok && !d.stopped; item, ok = d.nextHeld() {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package csvadapter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("name,age,email\n")
+	var expected []Person
+	for i := range 1000 {
+		if i == 500 {
+			input.WriteString("invalid,age,\n")
+			continue
+		}
+		fmt.Fprintf(&input, "person%d,%d,%s\n", i, i, fakemail)
+		expected = append(expected, Person{fmt.Sprintf("person%d", i), i, fakemail})
+	}
+
+	adapter, err := NewCSVAdapter[Person](Parallel(4), ReuseRecord(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := adapter.FromCSV(strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var people []Person
+	for person, err := range rows {
+		if err != nil {
+			if reading, ok := AsReadingError(err); !ok || reading.Line != 501 {
+				t.Errorf("expected error on line 501, got %v", err)
+			}
+			continue
+		}
+		people = append(people, person)
+	}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %d people in order, got %d", len(expected), len(people))
+	}
+
+	adapter, err = NewCSVAdapter[Person](Parallel(4), PreserveOrder(false), SkipInvalidRows(true), Limit(100))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people, err = adapter.UnmarshalAll(strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(people) != 100 {
+		t.Errorf("expected 100 people, got %d", len(people))
+	}
+
+	// stopping early releases the workers
+	rows, err = adapter.FromCSV(strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	for range rows {
+		break
+	}
+}
@@ -0,0 +1,61 @@
+package csvadapter
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// pools of the scratch state of reading and writing rows, shared by all adapters
+var (
+	// bufferedWriters holds the bufio.Writers of the default size
+	bufferedWriters = sync.Pool{New: func() any { return bufio.NewWriterSize(nil, defaultBufferSize) }}
+	// scratchBuffers holds the buffers cells are formatted in
+	scratchBuffers = sync.Pool{New: func() any { return new([]byte) }}
+	// records holds record slices
+	records = sync.Pool{New: func() any { return new([]string) }}
+)
+
+// getBufferedWriter returns a bufio.Writer of at least size bytes writing to writer,
+// and the function releasing it once it is flushed and no longer used
+func getBufferedWriter(writer io.Writer, size int) (*bufio.Writer, func()) {
+	if size > defaultBufferSize {
+		return bufio.NewWriterSize(writer, size), func() {}
+	}
+	buffered := bufferedWriters.Get().(*bufio.Writer)
+	buffered.Reset(writer)
+	return buffered, func() {
+		buffered.Reset(nil)
+		bufferedWriters.Put(buffered)
+	}
+}
+
+// getScratchBuffer returns an empty scratch buffer
+func getScratchBuffer() *[]byte {
+	buf := scratchBuffers.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putScratchBuffer releases a scratch buffer, large buffers are dropped
+func putScratchBuffer(buf *[]byte) {
+	if cap(*buf) <= 1<<10 {
+		scratchBuffers.Put(buf)
+	}
+}
+
+// getRecord returns a record of n empty cells
+func getRecord(n int) *[]string {
+	record := records.Get().(*[]string)
+	if cap(*record) < n {
+		*record = make([]string, n)
+	}
+	*record = (*record)[:n]
+	return record
+}
+
+// putRecord releases a record, its cells are cleared so they can be collected
+func putRecord(record *[]string) {
+	clear(*record)
+	records.Put(record)
+}
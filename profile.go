@@ -0,0 +1,145 @@
+package csvadapter
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// profileSamples is the number of sample values of each column of a Report
+const profileSamples = 5
+
+// Report is the profile of a csv file returned by Profile
+type Report struct {
+	Rows      int             // records read, excluding the header
+	Malformed int             // records that could not be parsed
+	Columns   []ColumnProfile // columns of the file, in order
+}
+
+// ColumnProfile describes the cells of a column of a Report
+type ColumnProfile struct {
+	Name        string   // name of the column in the header, or the alias of its field without header
+	Field       string   // name of the field of the column, empty if it is not mapped
+	Type        string   // type of the field, or the type most cells can be parsed as
+	NullRatio   float64  // share of the rows whose cell is empty, null or missing
+	Consistency float64  // share of the non-empty cells that can be parsed as Type
+	Samples     []string // first distinct non-empty cells
+}
+
+// columnProfile collects the profile of a column
+type columnProfile struct {
+	field   int   // index of the field of the column, -1 if it is not mapped
+	empty   int   // empty cells
+	filled  int   // non-empty cells
+	valid   int   // non-empty cells parsed as the type of the field
+	matches []int // non-empty cells matching each of inferredTypes, for columns without field
+	samples []string
+}
+
+// Profile reads a csv file and describes its columns, e.g. to check a file
+// before importing it: the share of empty cells, of cells that can be parsed,
+// and sample values
+//
+// the cells of mapped columns are parsed like the fields of the adapter (the tag
+// options apply, not the constraints). The types of the other columns are
+// guessed like by InferSchema. Malformed records are counted, not reported.
+func (c *CSVAdapter[T]) Profile(reader io.Reader) (*Report, error) {
+	d, err := c.NewDecoder(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	var columns []columnProfile
+	addColumns := func(n int) {
+		for len(columns) < n {
+			column := columnProfile{field: -1, matches: make([]int, len(inferredTypes))}
+			if i := slices.Index(d.columns, len(columns)); i >= 0 {
+				column.field = i
+			}
+			columns = append(columns, column)
+		}
+	}
+	addColumns(max(len(d.header), slices.Max(append([]int{-1}, d.columns...))+1))
+
+	report := &Report{}
+	s := reflect.New(c.structType).Elem()
+	for {
+		r, err := d.nextRecord()
+		var parseErr *csv.ParseError
+		if err == io.EOF {
+			break
+		} else if errors.As(err, &parseErr) {
+			report.Malformed++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		report.Rows++
+		addColumns(len(r.record))
+		s.SetZero()
+		for i := range columns {
+			column := &columns[i]
+			value := ""
+			if i < len(r.record) {
+				value = r.record[i]
+			}
+			var f *field
+			if column.field >= 0 {
+				f = &c.fields[column.field]
+				value = c.normalizeCell(f, value)
+			}
+			if value == "" {
+				column.empty++
+				continue
+			}
+			column.filled++
+			if len(column.samples) < profileSamples && !slices.Contains(column.samples, value) {
+				column.samples = append(column.samples, value)
+			}
+			if f == nil {
+				for j, t := range inferredTypes {
+					if t.matches(value) {
+						column.matches[j]++
+					}
+				}
+			} else if d.decodeField(s, f, fieldByIndex(s, f.index), value) == nil {
+				column.valid++
+			}
+		}
+	}
+
+	report.Columns = make([]ColumnProfile, len(columns))
+	for i, column := range columns {
+		profile := ColumnProfile{Name: "column" + strconv.Itoa(i+1), Samples: column.samples, Consistency: 1}
+		if i < len(d.header) {
+			profile.Name = d.header[i]
+		}
+		if column.field >= 0 {
+			f := c.fields[column.field]
+			profile.Field = f.name
+			profile.Type = c.structType.FieldByIndex(f.index).Type.String()
+			if d.header == nil {
+				profile.Name = f.alias
+			}
+			if column.filled > 0 {
+				profile.Consistency = float64(column.valid) / float64(column.filled)
+			}
+		} else {
+			profile.Type = "string"
+			best := slices.Index(column.matches, slices.Max(column.matches))
+			if column.matches[best] > 0 {
+				profile.Type = inferredTypes[best].typ.String()
+				profile.Consistency = float64(column.matches[best]) / float64(column.filled)
+			}
+		}
+		if report.Rows > 0 {
+			profile.NullRatio = float64(column.empty) / float64(report.Rows)
+		}
+		report.Columns[i] = profile
+	}
+	return report, nil
+}
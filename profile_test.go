@@ -0,0 +1,47 @@
+package csvadapter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	data := "name,age,email,score\n" +
+		"John Doe,30," + fakemail + ",1.5\n" +
+		"Jane Smith,unknown,,2\n" +
+		"\"Broken,40,,3\n"
+	report, err := adapter.Profile(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to profile: %v", err)
+	}
+	expected := &Report{
+		Rows:      2,
+		Malformed: 1,
+		Columns: []ColumnProfile{
+			{Name: "name", Field: "Name", Type: "string", Consistency: 1, Samples: []string{"John Doe", "Jane Smith"}},
+			{Name: "age", Field: "Age", Type: "int", Consistency: 0.5, Samples: []string{"30", "unknown"}},
+			{Name: "email", Field: "Email", Type: "string", NullRatio: 0.5, Consistency: 1, Samples: []string{fakemail}},
+			{Name: "score", Type: "float64", Consistency: 1, Samples: []string{"1.5", "2"}},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report)
+	}
+
+	dynamic, err := NewDynamicAdapter([]Column{{Name: "score", Type: reflect.TypeFor[int]()}})
+	if err != nil {
+		t.Fatalf("failed to create dynamic adapter: %v", err)
+	}
+	report, err = dynamic.Profile(strings.NewReader("score\n1\n2.5\n\n3\n"))
+	if err != nil {
+		t.Fatalf("failed to profile: %v", err)
+	}
+	if column := report.Columns[0]; column.Type != "int" || column.Consistency != 2.0/3 || report.Rows != 3 {
+		t.Errorf("unexpected profile %+v", report)
+	}
+}
@@ -0,0 +1,154 @@
+package csvadapter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuotePolicy defines which fields ToCSV quotes
+type QuotePolicy int
+
+const (
+	QuoteMinimal    QuotePolicy = iota // only fields that need quotes, like encoding/csv (default)
+	QuoteAll                           // every field
+	QuoteNonNumeric                    // every field whose type is not a number, and the null and empty representations
+	QuoteNone                          // no field, fields that need quotes are rejected with ErrUnquotableField
+)
+
+// newRecordWriter creates the record writer matching the quote policy
+//
+// the returned function releases the buffer of the record writer once it is flushed.
+func (c csvAdapterOptions) newRecordWriter(writer io.Writer) (RecordWriter, func()) {
+	// encoding/csv buffers its output with the given bufio.Writer if it is large enough
+	buffered, release := getBufferedWriter(writer, c.writeBufferSize)
+	if c.quoting == QuoteMinimal {
+		csvWriter := csv.NewWriter(buffered)
+		c.applyWriter(csvWriter)
+		return csvWriter, release
+	}
+	return &quotingWriter{
+		w:       buffered,
+		comma:   c.comma,
+		useCRLF: c.useCRLF,
+		policy:  c.quoting,
+	}, release
+}
+
+// quotingWriter writes csv records with other quote policies than encoding/csv
+type quotingWriter struct {
+	w       *bufio.Writer
+	comma   rune
+	useCRLF bool
+	policy  QuotePolicy
+	numeric []numericColumn // columns of number fields, left unquoted by QuoteNonNumeric
+}
+
+// numericColumn is a column of a number field
+type numericColumn struct {
+	numeric   bool     // if the field is a number
+	sentinels []string // cells of the column that are not numbers, e.g. the null representation
+}
+
+// isNumber reports whether a cell of the column is a number
+func (n numericColumn) isNumber(cell string) bool {
+	return n.numeric && !slices.Contains(n.sentinels, cell)
+}
+
+func (q *quotingWriter) Write(record []string) error {
+	return q.writeRecord(record, q.numeric)
+}
+
+// writeRecord writes a record, numeric being its columns of number fields
+func (q *quotingWriter) writeRecord(record []string, numeric []numericColumn) error {
+	for i, field := range record {
+		if i > 0 {
+			if _, err := q.w.WriteRune(q.comma); err != nil {
+				return err
+			}
+		}
+		if err := q.writeField(field, i < len(numeric) && numeric[i].isNumber(field)); err != nil {
+			return err
+		}
+	}
+	lineEnd := "\n"
+	if q.useCRLF {
+		lineEnd = "\r\n"
+	}
+	_, err := q.w.WriteString(lineEnd)
+	return err
+}
+
+// writeField writes a field, quoting it if required by the policy or its content
+//
+// the type of the field decides if it is a number, not its content, so that
+// strings such as "00123" or "NaN" are quoted by QuoteNonNumeric; only the null
+// and empty representations of number fields (e.g. "N/A") are quoted as well.
+func (q *quotingWriter) writeField(field string, numeric bool) error {
+	quote := q.needsQuotes(field)
+	switch q.policy {
+	case QuoteAll:
+		quote = true
+	case QuoteNonNumeric:
+		quote = quote || !numeric
+	case QuoteNone:
+		if quote {
+			return errors.Join(ErrUnquotableField, fmt.Errorf("field %q", field))
+		}
+	}
+	if !quote {
+		_, err := q.w.WriteString(field)
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range field {
+		switch r {
+		case '"':
+			b.WriteString(`""`)
+		case '\r':
+			if !q.useCRLF {
+				b.WriteByte('\r')
+			}
+		case '\n':
+			if q.useCRLF {
+				b.WriteString("\r\n")
+			} else {
+				b.WriteByte('\n')
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	_, err := q.w.WriteString(b.String())
+	return err
+}
+
+// needsQuotes reports whether a field must be quoted, like encoding/csv does
+func (q *quotingWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, q.comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+func (q *quotingWriter) Flush() {
+	q.w.Flush()
+}
+
+func (q *quotingWriter) Error() error {
+	_, err := q.w.Write(nil)
+	return err
+}
@@ -0,0 +1,115 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestQuoting(t *testing.T) {
+	type Row struct {
+		Name  string  `csva:"name"`
+		Score float64 `csva:"score"`
+		Note  string  `csva:"note,omitempty"`
+	}
+	rows := []Row{{"John \"JD\" Doe", 1.5, ""}, {"Jane", 2, "a,b"}}
+
+	tests := []struct {
+		policy   QuotePolicy
+		expected string
+	}{
+		{QuoteMinimal, "name,score,note\n\"John \"\"JD\"\" Doe\",1.500000,\nJane,2.000000,\"a,b\"\n"},
+		{QuoteAll, "\"name\",\"score\",\"note\"\n\"John \"\"JD\"\" Doe\",\"1.500000\",\"\"\n\"Jane\",\"2.000000\",\"a,b\"\n"},
+		{QuoteNonNumeric, "\"name\",\"score\",\"note\"\n\"John \"\"JD\"\" Doe\",1.500000,\"\"\n\"Jane\",2.000000,\"a,b\"\n"},
+	}
+	for _, test := range tests {
+		adapter, err := NewCSVAdapter[Row](Quoting(test.policy))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		writer := &bytes.Buffer{}
+		if err := adapter.MarshalAll(writer, rows); err != nil {
+			t.Fatalf("failed to write csv: %v", err)
+		}
+		if writer.String() != test.expected {
+			t.Errorf("policy %d: expected %q, got %q", test.policy, test.expected, writer.String())
+		}
+	}
+
+	adapter, err := NewCSVAdapter[Row](Quoting(QuoteNone))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, rows[1:2]); err == nil || !errors.Is(err, ErrUnquotableField) {
+		t.Errorf("expected %v, got %v", ErrUnquotableField, err)
+	}
+	writer.Reset()
+	if err := adapter.MarshalAll(writer, []Row{{"Jane", 2, "ab"}}); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if expected := "name,score,note\nJane,2.000000,ab\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
+
+func TestQuotingRoundTrip(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](Quoting(QuoteAll), UseCRLF(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	people := []Person{{"John\nDoe", age, fakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, people); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	read, err := adapter.UnmarshalAll(writer)
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(read) != 1 || read[0] != people[0] {
+		t.Errorf("expected %v, got %v", people, read)
+	}
+}
+
+func TestQuoteNonNumericTypes(t *testing.T) {
+	type Row struct {
+		Code  string  `csva:"code"`
+		Count *int    `csva:"count"`
+		Ratio float64 `csva:"ratio"`
+		Hex   int     `csva:"hex,base=16"`
+		Flag  bool    `csva:"flag"`
+	}
+	adapter, err := NewCSVAdapter[Row](Quoting(QuoteNonNumeric))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	count := 3
+	writer := &bytes.Buffer{}
+	rows := []Row{{"00123", &count, 0.5, 255, true}, {"NaN", nil, 1, 1, false}}
+	if err := adapter.MarshalAll(writer, rows); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	expected := "\"code\",\"count\",\"ratio\",\"hex\",\"flag\"\n" +
+		"\"00123\",3,0.500000,\"ff\",\"true\"\n" +
+		"\"NaN\",,1.000000,\"1\",\"false\"\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+	// the null and empty representations of number fields are not numbers
+	type Sentinels struct {
+		Count *int `csva:"count"`
+		Level *int `csva:"level,omitempty,empty=-,null="`
+	}
+	sentinels, err := NewCSVAdapter[Sentinels](Quoting(QuoteNonNumeric), NullString("N/A"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	writer.Reset()
+	if err := sentinels.MarshalAll(writer, []Sentinels{{&count, &count}, {nil, nil}}); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if expected := "\"count\",\"level\"\n3,3\n\"N/A\",\"-\"\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
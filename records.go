@@ -0,0 +1,38 @@
+package csvadapter
+
+// RecordReader reads the records of a tabular file, the header first if the file has one
+//
+// it is implemented by *csv.Reader. Read returns io.EOF once there are no more
+// records; errors wrapping *csv.ParseError are reported for the row, other
+// errors are reported by the decoder as is. If the reader has an
+// InputOffset() int64 method, it is used for the byte offsets of the rows.
+type RecordReader interface {
+	Read() (record []string, err error)
+}
+
+// RecordWriter writes the records of a tabular file, it is implemented by *csv.Writer
+type RecordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// NewRecordDecoder creates a Decoder reading the records of another tabular
+// format than csv, e.g. a spreadsheet or fixed-width columns
+//
+// the fields are mapped like for csv files. reader yields records already split
+// into cells, so the options of the csv layer (e.g. Comma, WithCompression,
+// Charset or SkipRows) have no effect.
+func (c *CSVAdapter[T]) NewRecordDecoder(reader RecordReader) (*Decoder[T], error) {
+	return c.newDecoder(reader, 0, reader)
+}
+
+// NewRecordEncoder creates an Encoder writing the records of another tabular
+// format than csv
+//
+// writer gets the formatted cells as is and decides how they are written, so
+// Comma, Quoting, WithCompression and WriteBOM have no effect. Closing the
+// encoder flushes writer.
+func (c *CSVAdapter[T]) NewRecordEncoder(writer RecordWriter) *Encoder[T] {
+	return c.newEncoder(writer, func() {}, func() error { return nil })
+}
@@ -0,0 +1,87 @@
+package csvadapter
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+// tableReader reads the records of a table held in memory
+type tableReader struct {
+	records [][]string
+}
+
+func (t *tableReader) Read() ([]string, error) {
+	if len(t.records) == 0 {
+		return nil, io.EOF
+	}
+	record := t.records[0]
+	t.records = t.records[1:]
+	return record, nil
+}
+
+// tableWriter collects records in memory
+type tableWriter struct {
+	records [][]string
+	flushed bool
+}
+
+func (t *tableWriter) Write(record []string) error {
+	t.records = append(t.records, slices.Clone(record))
+	return nil
+}
+
+func (t *tableWriter) Flush() {
+	t.flushed = true
+}
+
+func (t *tableWriter) Error() error {
+	return nil
+}
+
+func TestRecordBackends(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	table := [][]string{
+		{"email", "name", "age"},
+		{fakemail, name, "30"},
+		{"", "Jane Smith", "25"},
+	}
+	decoder, err := adapter.NewRecordDecoder(&tableReader{records: table})
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	var people []Person
+	for person, err := range decoder.All() {
+		if err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		people = append(people, person)
+	}
+	expected := []Person{{name, 30, fakemail}, {"Jane Smith", 25, ""}}
+	if !slices.Equal(people, expected) {
+		t.Errorf("expected %+v, got %+v", expected, people)
+	}
+
+	writer := &tableWriter{}
+	encoder := adapter.NewRecordEncoder(writer)
+	for _, person := range people {
+		if err := encoder.Encode(person); err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("failed to close encoder: %v", err)
+	}
+	written := [][]string{
+		{"name", "age", "email"},
+		{name, "30", fakemail},
+		{"Jane Smith", "25", ""},
+	}
+	if !writer.flushed || !slices.EqualFunc(writer.records, written, slices.Equal) {
+		t.Errorf("expected %v, got %v", written, writer.records)
+	}
+}
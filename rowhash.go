@@ -0,0 +1,39 @@
+package csvadapter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"reflect"
+)
+
+// rowHash returns the hex SHA-256 of the canonical form of the fields of the
+// struct s: their cells as formatted when writing (empty for null values and
+// fields that cannot be formatted), each prefixed with its length
+//
+// the hash of a row is the same when reading and writing it, whatever the
+// formatting of its cells in the file (e.g. "1.50" and "1.5").
+func (c *CSVAdapter[T]) rowHash(s reflect.Value, buf *[]byte) string {
+	var marshaler FieldMarshaler
+	if c.structType.Implements(reflect.TypeFor[FieldMarshaler]()) {
+		marshaler = s.Interface().(FieldMarshaler)
+	}
+	hash := sha256.New()
+	var length [binary.MaxVarintLen64]byte
+	for i, f := range c.fields {
+		cell := ""
+		if field, err := s.FieldByIndexErr(f.index); err == nil && !isNull(field) {
+			cell, _ = encodeField(marshaler, &c.fields[i], field, buf)
+		}
+		hash.Write(binary.AppendUvarint(length[:0], uint64(len(cell))))
+		io.WriteString(hash, cell)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isRowHashColumn reports whether a column of a header is the column of the RowHashColumn option
+func (c *CSVAdapter[T]) isRowHashColumn(column string) bool {
+	name := c.options.rowHashColumn
+	return name != "" && c.options.headerKey(column) == c.options.headerKey(name)
+}
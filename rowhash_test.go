@@ -0,0 +1,96 @@
+package csvadapter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type HashedProduct struct {
+	SKU   string  `csva:"sku"`
+	Price float64 `csva:"price"`
+	Hash  string  `csva:"-,row_hash"`
+}
+
+func TestRowHash(t *testing.T) {
+	adapter, err := NewCSVAdapter[HashedProduct](RowHashColumn("hash"))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	var buf bytes.Buffer
+	products := []HashedProduct{{SKU: "A1", Price: 1.5}, {SKU: "B2", Price: 3}}
+	if err := adapter.ToCSV(&buf, slices.Values(products)); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "sku,price,hash" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	written := strings.Split(lines[1], ",")[2]
+	if len(written) != 64 || written == strings.Split(lines[2], ",")[2] {
+		t.Fatalf("unexpected hashes in %q", buf.String())
+	}
+
+	read, err := adapter.UnmarshalAll(&buf)
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if read[0].Hash != written {
+		t.Errorf("expected hash %s when reading, got %s", written, read[0].Hash)
+	}
+
+	// the hash does not depend on the formatting of the cells
+	read, err = adapter.UnmarshalAll(strings.NewReader("price,sku\n1.50,A1\n1.6,A1\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if read[0].Hash != written || read[1].Hash == written {
+		t.Errorf("expected hash %s for the same row only, got %s and %s", written, read[0].Hash, read[1].Hash)
+	}
+
+	var jsonl bytes.Buffer
+	if err := adapter.ToJSONL(&jsonl, slices.Values(products[:1])); err != nil {
+		t.Fatalf("failed to write jsonl: %v", err)
+	}
+	if expected := `{"sku":"A1","price":1.500000,"hash":"` + written + "\"}\n"; jsonl.String() != expected {
+		t.Errorf("expected %q, got %q", expected, jsonl.String())
+	}
+
+	if _, err := NewCSVAdapter[struct {
+		Hash int `csva:"-,row_hash"`
+	}](); err == nil {
+		t.Errorf("expected error for a non-string row hash field")
+	}
+}
+
+func TestRowHashAppendToFile(t *testing.T) {
+	adapter, err := NewCSVAdapter[HashedProduct](RowHashColumn("hash"), StrictHeader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "products.csv")
+	for _, product := range []HashedProduct{{SKU: "A1", Price: 1.5}, {SKU: "B2", Price: 3}} {
+		if err := adapter.AppendToFile(path, slices.Values([]HashedProduct{product})); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer file.Close()
+	read, err := adapter.UnmarshalAll(file)
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(read) != 2 || read[1].SKU != "B2" || len(read[1].Hash) != 64 {
+		t.Errorf("unexpected rows %+v", read)
+	}
+	if err := adapter.ValidateHeader([]string{"sku", "price", "hash"}); err != nil {
+		t.Errorf("unexpected error for the row hash column: %v", err)
+	}
+}
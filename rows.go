@@ -0,0 +1,66 @@
+package csvadapter
+
+import (
+	"database/sql"
+	"io"
+	"iter"
+)
+
+// rowsReader reads the results of a query as records, the column names first
+type rowsReader struct {
+	rows   *sql.Rows
+	header bool // if the column names are read as the header
+	values []sql.NullString
+	dest   []any
+	done   bool
+}
+
+func (r *rowsReader) Read() ([]string, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if r.dest == nil {
+		columns, err := r.rows.Columns()
+		if err != nil {
+			r.done = true
+			return nil, err
+		}
+		r.values = make([]sql.NullString, len(columns))
+		r.dest = make([]any, len(columns))
+		for i := range r.values {
+			r.dest[i] = &r.values[i]
+		}
+		if r.header {
+			return columns, nil
+		}
+	}
+	if !r.rows.Next() {
+		r.done = true
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err := r.rows.Scan(r.dest...); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(r.values))
+	for i, value := range r.values {
+		record[i] = value.String // NULL is an empty cell
+	}
+	return record, nil
+}
+
+// FromRows reads structs from the results of a query
+//
+// the columns of the query are matched with the aliases of the fields like a
+// header, and the values are parsed like cells: they are converted to strings
+// by database/sql (e.g. times in RFC 3339) and NULL is an empty cell.
+// Closing rows is left to the caller.
+func (c *CSVAdapter[T]) FromRows(rows *sql.Rows) (iter.Seq2[T, error], error) {
+	decoder, err := c.newDecoder(&rowsReader{rows: rows, header: !c.positional && !c.options.noHeader}, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.All(), nil
+}
@@ -0,0 +1,92 @@
+package csvadapter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"slices"
+	"testing"
+)
+
+// tableDriver is a database/sql driver answering every query with the same table,
+// the first record being the column names
+type tableDriver struct {
+	table [][]driver.Value
+}
+
+func (d tableDriver) Open(string) (driver.Conn, error) { return tableConn(d), nil }
+
+type tableConn tableDriver
+
+func (c tableConn) Prepare(string) (driver.Stmt, error) { return tableStmt(c), nil }
+func (c tableConn) Close() error                        { return nil }
+func (c tableConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type tableStmt tableConn
+
+func (s tableStmt) Close() error                               { return nil }
+func (s tableStmt) NumInput() int                              { return -1 }
+func (s tableStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s tableStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &tableRows{table: s.table[1:], columns: s.table[0]}, nil
+}
+
+type tableRows struct {
+	columns []driver.Value
+	table   [][]driver.Value
+}
+
+func (r *tableRows) Columns() []string {
+	columns := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		columns[i] = column.(string)
+	}
+	return columns
+}
+func (r *tableRows) Close() error { return nil }
+func (r *tableRows) Next(dest []driver.Value) error {
+	if len(r.table) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.table[0])
+	r.table = r.table[1:]
+	return nil
+}
+
+func TestFromRows(t *testing.T) {
+	sql.Register("csvadapter-table", tableDriver{table: [][]driver.Value{
+		{"email", "name", "age"},
+		{fakemail, name, int64(30)},
+		{nil, "Jane Smith", int64(25)},
+	}})
+	db, err := sql.Open("csvadapter-table", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := db.Query("SELECT email, name, age FROM people")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	defer rows.Close()
+	people, err := adapter.FromRows(rows)
+	if err != nil {
+		t.Fatalf("failed to read rows: %v", err)
+	}
+	var read []Person
+	for person, err := range people {
+		if err != nil {
+			t.Fatalf("failed to read rows: %v", err)
+		}
+		read = append(read, person)
+	}
+	expected := []Person{{name, 30, fakemail}, {"Jane Smith", 25, ""}}
+	if !slices.Equal(read, expected) {
+		t.Errorf("expected %+v, got %+v", expected, read)
+	}
+}
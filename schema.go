@@ -0,0 +1,228 @@
+package csvadapter
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+)
+
+// FieldInfo describes how a struct field is mapped to a csv column
+type FieldInfo struct {
+	Name      string       // name of the field in the struct, dotted for flattened fields
+	Alias     string       // name of the column in the csv
+	Type      reflect.Type // type of the field
+	Index     []int        // index path of the field in the struct
+	Column    int          // position of the column for positional fields, -1 if not set
+	OmitEmpty bool         // if the cells can be empty
+	Required  bool         // if the column must exist and the cells must not be empty
+}
+
+// Fields returns the fields mapped to columns, in the order of the columns
+//
+// metadata fields and the rest field are not included.
+func (c *CSVAdapter[T]) Fields() []FieldInfo {
+	infos := make([]FieldInfo, len(c.fields))
+	for i, f := range c.fields {
+		infos[i] = FieldInfo{
+			Name:      f.name,
+			Alias:     f.alias,
+			Type:      c.structType.FieldByIndex(f.index).Type,
+			Index:     slices.Clone(f.index),
+			Column:    f.column,
+			OmitEmpty: f.omitEmpty,
+			Required:  f.required,
+		}
+	}
+	return infos
+}
+
+// Header returns the columns written by ToCSV, in order
+//
+// the columns of the rest field are not included, as they depend on the data.
+// When the fields have both an alias and an index, the aliases are at their
+// positions and the positions without field are empty.
+func (c *CSVAdapter[T]) Header() []string {
+	if c.fallback {
+		width := 0
+		for _, f := range c.fields {
+			width = max(width, f.column+1)
+		}
+		header := make([]string, width)
+		for _, f := range c.fields {
+			header[f.column] = f.alias
+		}
+		return header
+	}
+	header := make([]string, len(c.fields))
+	for i, f := range c.fields {
+		header[i] = f.alias
+	}
+	return header
+}
+
+// HeaderDiff describes how a header differs from the fields of an adapter
+type HeaderDiff struct {
+	Missing         []string // aliases of the fields without column that cannot be omitted
+	MissingOptional []string // aliases of the fields without column that can be omitted
+	Unexpected      []string // columns not mapped to any field
+	Duplicated      []string // columns present more than once
+	Reordered       []string // aliases of the fields whose column is not in the order of Header
+}
+
+// Matches reports whether the header has exactly the columns of the adapter, in order
+func (d HeaderDiff) Matches() bool {
+	return len(d.Missing) == 0 && len(d.MissingOptional) == 0 && len(d.Unexpected) == 0 &&
+		len(d.Duplicated) == 0 && len(d.Reordered) == 0
+}
+
+// CompareHeader compares a header to the fields without reading any row
+//
+// columns are matched like by FromCSV, with the header normalization and duplicate
+// header options of the adapter. Positional adapters accept any header.
+func (c *CSVAdapter[T]) CompareHeader(header []string) HeaderDiff {
+	var diff HeaderDiff
+	if c.positional {
+		return diff
+	}
+	positions := make(map[string][]int, len(header))
+	for i, h := range header {
+		key := c.options.headerKey(h)
+		positions[key] = append(positions[key], i)
+		if len(positions[key]) == 2 {
+			diff.Duplicated = append(diff.Duplicated, h)
+		}
+	}
+
+	known := make(map[string]bool, len(c.fields))
+	used := make(map[string]int) // number of columns bound per key, for DuplicateSequential
+	var found []string           // aliases of the fields found, in the order of the fields
+	columns := make(map[string]int, len(c.fields))
+	for _, f := range c.fields {
+		key := c.options.headerKey(f.alias)
+		known[key] = true
+		indices := positions[key]
+		isFound, index := len(indices) > 0, -1
+		if isFound {
+			index = indices[len(indices)-1]
+		}
+		switch c.options.duplicateHeaders {
+		case DuplicateFirstWins:
+			if isFound {
+				index = indices[0]
+			}
+		case DuplicateSequential:
+			isFound = used[key] < len(indices)
+			if isFound {
+				index = indices[used[key]]
+			}
+			used[key]++
+		}
+		if !isFound && c.fallback && f.column < len(header) {
+			continue // mapped to its position
+		}
+		switch {
+		case isFound:
+			found = append(found, f.alias)
+			columns[f.alias] = index
+		case !c.canOmitColumn(&f):
+			diff.Missing = append(diff.Missing, f.alias)
+		default:
+			diff.MissingOptional = append(diff.MissingOptional, f.alias)
+		}
+	}
+
+	for _, h := range header {
+		if !known[c.options.headerKey(h)] && !c.isRowHashColumn(h) {
+			diff.Unexpected = append(diff.Unexpected, h)
+		}
+	}
+
+	// the fields out of the longest run of columns in order are the ones that moved
+	inOrder := longestIncreasing(found, func(alias string) int { return columns[alias] })
+	for i, alias := range found {
+		if !inOrder[i] {
+			diff.Reordered = append(diff.Reordered, alias)
+		}
+	}
+	return diff
+}
+
+// longestIncreasing marks the elements of a longest subsequence of items with increasing keys
+func longestIncreasing[E any](items []E, key func(E) int) []bool {
+	length := make([]int, len(items)) // length of the longest subsequence ending at each item
+	prev := make([]int, len(items))   // previous item of that subsequence, -1 if none
+	last := -1
+	for i := range items {
+		length[i], prev[i] = 1, -1
+		for j := range i {
+			if key(items[j]) < key(items[i]) && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if last < 0 || length[i] > length[last] {
+			last = i
+		}
+	}
+	marked := make([]bool, len(items))
+	for i := last; i >= 0; i = prev[i] {
+		marked[i] = true
+	}
+	return marked
+}
+
+// ValidateHeader checks a header against the fields without reading any row
+//
+// unlike FromCSV, which stops at the first problem, it reports every missing
+// column (ErrFieldNotFound), every duplicate column with the DuplicateError
+// policy (ErrDuplicateColumn) and every unknown column with the StrictHeader
+// option (ErrUnknownColumn). Positional adapters accept any header.
+func (c *CSVAdapter[T]) ValidateHeader(header []string) error {
+	diff := c.CompareHeader(header)
+	var errs []error
+	if c.options.duplicateHeaders == DuplicateError {
+		for _, column := range diff.Duplicated {
+			errs = append(errs, errors.Join(ErrDuplicateColumn, fmt.Errorf("column %s", column)))
+		}
+	}
+	for _, alias := range diff.Missing {
+		errs = append(errs, errors.Join(ErrFieldNotFound, fmt.Errorf("field %s", alias)))
+	}
+	if c.options.strictHeader && c.restField == nil {
+		for _, column := range diff.Unexpected {
+			errs = append(errs, errors.Join(ErrUnknownColumn, fmt.Errorf("column %s", column)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateReader reads the header of a csv file and checks it with ValidateHeader
+//
+// no row is read, so it is cheap enough to reject uploads before processing them.
+func (c *CSVAdapter[T]) ValidateReader(reader io.Reader) error {
+	if c.positional || c.options.noHeader {
+		return nil
+	}
+	header, err := c.readHeader(reader)
+	if err != nil {
+		return err
+	}
+	return c.ValidateHeader(header)
+}
+
+// readHeader reads the header of the csv file read by reader
+func (c *CSVAdapter[T]) readHeader(reader io.Reader) ([]string, error) {
+	input, _, err := c.options.openReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	csvReader := csv.NewReader(input)
+	c.options.applyReader(csvReader)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.Join(ErrReadingCSVLines, err)
+	}
+	return header, nil
+}
@@ -0,0 +1,125 @@
+package csvadapter
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	type Address struct {
+		City string `csva:"city"`
+	}
+	type PersonWithAddress struct {
+		Name    string   `csva:"name,required"`
+		Age     int      `csva:"age"`
+		Billing *Address `csva:"billing,flatten,omitempty"`
+	}
+
+	adapter, err := NewCSVAdapter[PersonWithAddress]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	fields := adapter.Fields()
+	if len(fields) != 3 {
+		t.Fatalf("expected 3 fields, got %d", len(fields))
+	}
+	if f := fields[0]; f.Alias != "name" || f.Type != reflect.TypeFor[string]() || !f.Required || f.OmitEmpty {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if f := fields[1]; f.Name != "Age" || f.Type != reflect.TypeFor[int]() || !slices.Equal(f.Index, []int{1}) {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if f := fields[2]; f.Alias != "billing_city" || f.Type != reflect.TypeFor[string]() || !slices.Equal(f.Index, []int{2, 0}) || !f.OmitEmpty {
+		t.Errorf("unexpected field %+v", f)
+	}
+
+	expected := []string{"name", "age", "billing_city"}
+	if header := adapter.Header(); !slices.Equal(header, expected) {
+		t.Errorf("expected header %v, got %v", expected, header)
+	}
+
+	// the returned fields are copies
+	fields[1].Index[0] = 5
+	if adapter.Fields()[1].Index[0] != 1 {
+		t.Errorf("the fields of the adapter were modified")
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](StrictHeader(true), DuplicateHeaders(DuplicateError))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	if err := adapter.ValidateHeader([]string{"age", "name"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// every problem is reported
+	err = adapter.ValidateHeader([]string{"email", "phone", "email"})
+	for _, expected := range []error{ErrFieldNotFound, ErrUnknownColumn, ErrDuplicateColumn} {
+		if !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+	for _, column := range []string{"field name", "field age", "column phone", "column email"} {
+		if !strings.Contains(err.Error(), column) {
+			t.Errorf("expected %q to be reported, got %v", column, err)
+		}
+	}
+}
+
+func TestValidateReader(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	// the rows are not read
+	if err := adapter.ValidateReader(strings.NewReader("name,age\nJohn Doe,invalid\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := adapter.ValidateReader(strings.NewReader("name,email\n")); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, got %v", err)
+	}
+	if err := adapter.ValidateReader(strings.NewReader("")); !errors.Is(err, ErrReadingCSVLines) {
+		t.Errorf("expected ErrReadingCSVLines, got %v", err)
+	}
+}
+
+func TestCompareHeader(t *testing.T) {
+	type Row struct {
+		A string `csva:"a"`
+		B string `csva:"b"`
+		C string `csva:"c"`
+		D string `csva:"d,omitempty"`
+		E string `csva:"e"`
+	}
+	adapter, err := NewCSVAdapter[Row](DuplicateHeaders(DuplicateFirstWins))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	if diff := adapter.CompareHeader([]string{"a", "b", "c", "d", "e"}); !diff.Matches() {
+		t.Errorf("expected the header to match, got %+v", diff)
+	}
+
+	diff := adapter.CompareHeader([]string{"b", "c", "a", "x", "c"})
+	expected := HeaderDiff{
+		Missing:         []string{"e"},
+		MissingOptional: []string{"d"},
+		Unexpected:      []string{"x"},
+		Duplicated:      []string{"c"},
+		Reordered:       []string{"a"},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected %+v, got %+v", expected, diff)
+	}
+	if diff.Matches() {
+		t.Errorf("expected the header not to match")
+	}
+}
@@ -0,0 +1,105 @@
+package csvadapter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// defaultSampleSize is the size of the sample of SniffDialect if none is given
+const defaultSampleSize = 64 * 1024
+
+// sniffedCommas are the field separators SniffDialect chooses from, by preference
+var sniffedCommas = []rune{',', ';', '\t', '|'}
+
+// SniffDialect guesses the dialect of a csv file from its first sampleSize bytes
+// (64 KiB if sampleSize is not positive)
+//
+// the field separator is the one of comma, semicolon, tab and pipe splitting
+// the most records of the sample into the same number of fields, the line
+// ending is the one of the first line. The sample is consumed from r, so to
+// read the file afterwards, sniff a peeked sample (e.g. with bufio.Reader.Peek)
+// and apply the dialect with WithDialect.
+func SniffDialect(r io.Reader, sampleSize int) (Dialect, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultSampleSize
+	}
+	sample, err := io.ReadAll(io.LimitReader(r, int64(sampleSize)))
+	if err != nil {
+		return Dialect{}, errors.Join(ErrReadingCSV, err)
+	}
+	sample = bytes.TrimPrefix(sample, utf8BOM)
+	if len(sample) == sampleSize {
+		// the last line may be cut
+		if end := bytes.LastIndexByte(sample, '\n'); end >= 0 {
+			sample = sample[:end+1]
+		}
+	}
+
+	d := DefaultDialect
+	if end := bytes.IndexByte(sample, '\n'); end > 0 && sample[end-1] == '\r' {
+		d.UseCRLF = true
+	}
+	bestScore, bestFields := 0, 1
+	for _, comma := range sniffedCommas {
+		score, fields, lazy := sniffComma(sample, comma)
+		if fields > 1 && (score > bestScore || (score == bestScore && fields > bestFields)) {
+			bestScore, bestFields = score, fields
+			d.Comma, d.LazyQuotes = comma, lazy
+		}
+	}
+	if bestFields > 1 && quotesAll(sample, d.Comma, bestFields) {
+		d.Quoting = QuoteAll
+	}
+	return d, nil
+}
+
+// sniffComma parses the sample with the field separator comma
+//
+// it returns the number of records having the most common number of fields,
+// that number, and whether the sample can only be read with lazy quotes.
+func sniffComma(sample []byte, comma rune) (score, fields int, lazy bool) {
+	records, err := readSample(sample, comma, false)
+	if err != nil {
+		lazy = true
+		if records, err = readSample(sample, comma, true); err != nil {
+			return 0, 0, false
+		}
+	}
+	counts := make(map[int]int)
+	for _, record := range records {
+		counts[len(record)]++
+		if n := counts[len(record)]; n > score || (n == score && len(record) > fields) {
+			score, fields = n, len(record)
+		}
+	}
+	return score, fields, lazy
+}
+
+// readSample reads all the records of the sample
+func readSample(sample []byte, comma rune, lazyQuotes bool) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(sample))
+	reader.Comma = comma
+	reader.LazyQuotes = lazyQuotes
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// quotesAll reports whether every field of the lines of the sample is quoted
+func quotesAll(sample []byte, comma rune, fields int) bool {
+	separator := `"` + string(comma) + `"`
+	lines := 0
+	for _, line := range strings.Split(string(sample), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		lines++
+		if !strings.HasPrefix(line, `"`) || !strings.HasSuffix(line, `"`) || strings.Count(line, separator) != fields-1 {
+			return false
+		}
+	}
+	return lines > 0
+}
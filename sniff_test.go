@@ -0,0 +1,37 @@
+package csvadapter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSniffDialect(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected Dialect
+	}{
+		{"name,age\nJohn Doe,30\n", DefaultDialect},
+		{"name;age;note\r\nJohn Doe;30;\"a, b\"\r\nJane;25;x\r\n", Dialect{Comma: ';', UseCRLF: true}},
+		{"name\tage\nJohn, Doe\t30\n", Dialect{Comma: '\t'}},
+		{"\"name\"|\"age\"\n\"John Doe\"|\"30\"\n", Dialect{Comma: '|', Quoting: QuoteAll}},
+		{"name,note\nJohn,5\" tall\n", Dialect{Comma: ',', LazyQuotes: true}},
+		{"name\nJohn Doe\n", DefaultDialect},
+	} {
+		d, err := SniffDialect(strings.NewReader(tc.input), 0)
+		if err != nil {
+			t.Fatalf("failed to sniff %q: %v", tc.input, err)
+		}
+		if d != tc.expected {
+			t.Errorf("expected %+v for %q, got %+v", tc.expected, tc.input, d)
+		}
+	}
+
+	// the last line of a full sample is ignored, it may be cut
+	d, err := SniffDialect(strings.NewReader("a;b\n1;2\n3;4\nx,y,z,w,v"), 16)
+	if err != nil {
+		t.Fatalf("failed to sniff: %v", err)
+	}
+	if d.Comma != ';' {
+		t.Errorf("expected ';', got %q", d.Comma)
+	}
+}
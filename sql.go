@@ -0,0 +1,194 @@
+package csvadapter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"iter"
+	"strings"
+)
+
+// SQLSyntax is the quoting of the statements written by ToSQLInserts
+type SQLSyntax int
+
+const (
+	SQLStandard SQLSyntax = iota // "identifiers" and 'strings' with '' escaped, e.g. for PostgreSQL or SQLite
+	SQLMySQL                     // `identifiers` and 'strings' with \ and ' escaped by \, for MySQL and MariaDB
+)
+
+// sets the SQL syntax of ToSQLInserts
+//
+// the default SQLStandard follows ANSI SQL. MySQL does not read it with its
+// default sql_mode: backslashes start escape sequences in its strings and
+// double quotes delimit strings, so SQLMySQL must be used instead.
+func WithSQLSyntax(syntax SQLSyntax) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.sqlSyntax = syntax
+	}
+}
+
+// mysqlEscaper escapes the cells of MySQL string literals
+var mysqlEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`)
+
+// sqlInsertWriter writes the records of an Encoder as INSERT statements
+type sqlInsertWriter struct {
+	w       *bufio.Writer
+	table   string
+	syntax  SQLSyntax
+	columns func() ([]string, []bool) // columns of the records and if their cells are literals
+	keys    []string
+	literal []bool
+	prefix  string // INSERT INTO table (columns) VALUES
+	err     error
+}
+
+func (s *sqlInsertWriter) Write(record []string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.keys == nil {
+		s.keys, s.literal = s.columns()
+		var columns []string
+		for _, key := range s.keys {
+			if key != "" { // positions without field
+				columns = append(columns, s.syntax.quoteIdentifier(key))
+			}
+		}
+		s.prefix = "INSERT INTO " + s.syntax.quoteTable(s.table) + " (" + strings.Join(columns, ", ") + ") VALUES ("
+	}
+	s.w.WriteString(s.prefix)
+	first := true
+	for i, cell := range record {
+		if s.keys[i] == "" {
+			continue
+		}
+		if !first {
+			s.w.WriteString(", ")
+		}
+		first = false
+		switch {
+		case cell == "":
+			s.w.WriteString("NULL")
+		case s.literal[i] && json.Valid([]byte(cell)):
+			s.w.WriteString(cell)
+		default:
+			s.w.WriteString(s.syntax.quoteString(cell))
+		}
+	}
+	_, s.err = s.w.WriteString(");\n")
+	return s.err
+}
+
+func (s *sqlInsertWriter) Flush() {
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+}
+
+func (s *sqlInsertWriter) Error() error {
+	return s.err
+}
+
+// quoteIdentifier quotes an SQL identifier
+func (s SQLSyntax) quoteIdentifier(name string) string {
+	if s == SQLMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteTable quotes a table name, qualified by its schema or not
+func (s SQLSyntax) quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = s.quoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteString quotes a cell as an SQL string literal
+func (s SQLSyntax) quoteString(cell string) string {
+	if s == SQLMySQL {
+		return "'" + mysqlEscaper.Replace(cell) + "'"
+	}
+	return "'" + strings.ReplaceAll(cell, "'", "''") + "'"
+}
+
+// copyEscaper escapes the cells of the PostgreSQL COPY text format
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// copyWriter writes the records of an Encoder in the PostgreSQL COPY text format
+type copyWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (c *copyWriter) Write(record []string) error {
+	if c.err != nil {
+		return c.err
+	}
+	for i, cell := range record {
+		if i > 0 {
+			c.w.WriteByte('\t')
+		}
+		if cell == "" {
+			c.w.WriteString(`\N`)
+			continue
+		}
+		copyEscaper.WriteString(c.w, cell)
+	}
+	c.err = c.w.WriteByte('\n')
+	return c.err
+}
+
+func (c *copyWriter) Flush() {
+	if c.err == nil {
+		c.err = c.w.Flush()
+	}
+}
+
+func (c *copyWriter) Error() error {
+	return c.err
+}
+
+// ToSQLInserts writes structs as INSERT statements into table, one per row
+//
+// the columns are the aliases of the fields. Numbers and booleans are written
+// as literals (unless formatted in another base or with separators), the other
+// cells as strings, and nil and empty values as NULL whatever the null and
+// empty representations of the fields.
+// table can be qualified by its schema, e.g. "public.people". The statements
+// follow ANSI SQL (e.g. for PostgreSQL), or MySQL with the WithSQLSyntax option.
+func (c *CSVAdapter[T]) ToSQLInserts(writer io.Writer, table string, data iter.Seq[T]) error {
+	buffered, release := getBufferedWriter(writer, c.options.writeBufferSize)
+	sqlWriter := &sqlInsertWriter{w: buffered, table: table, syntax: c.options.sqlSyntax}
+	encoder := c.newEncoder(sqlWriter, release, func() error { return nil })
+	encoder.header, encoder.nullCells = false, true
+	sqlWriter.columns = func() ([]string, []bool) { return encoder.columns(false) }
+	return encodeAll(encoder, data)
+}
+
+// ToCopy writes structs in the text format of the PostgreSQL COPY command,
+// to load them with COPY table (columns) FROM STDIN
+//
+// the columns are those of Header, nil and empty values are written as \N (NULL)
+// whatever the null and empty representations of the fields.
+func (c *CSVAdapter[T]) ToCopy(writer io.Writer, data iter.Seq[T]) error {
+	buffered, release := getBufferedWriter(writer, c.options.writeBufferSize)
+	encoder := c.newEncoder(&copyWriter{w: buffered}, release, func() error { return nil })
+	encoder.header, encoder.nullCells = false, true
+	return encodeAll(encoder, data)
+}
+
+// CopyReader returns the structs in the text format of the PostgreSQL COPY
+// command as a stream, e.g. for pgx's CopyFrom
+//
+// the structs are encoded as the reader is read; errors are returned by Read.
+// The reader must be read until the end or closed.
+func (c *CSVAdapter[T]) CopyReader(data iter.Seq[T]) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(c.ToCopy(writer, data))
+	}()
+	return reader
+}
@@ -0,0 +1,106 @@
+package csvadapter
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestToSQLInserts(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, 30, fakemail}, {"Jane O'Neil", 25, ""}}
+	var output bytes.Buffer
+	if err := adapter.ToSQLInserts(&output, "public.people", slices.Values(people)); err != nil {
+		t.Fatalf("failed to write inserts: %v", err)
+	}
+	expected := `INSERT INTO "public"."people" ("name", "age", "email") VALUES ('John Doe', 30, '` + fakemail + `');` + "\n" +
+		`INSERT INTO "public"."people" ("name", "age", "email") VALUES ('Jane O''Neil', 25, NULL);` + "\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestToSQLInsertsSyntax(t *testing.T) {
+	people := []Person{{`C:\dir\`, 30, "it's"}}
+	tests := []struct {
+		syntax   SQLSyntax
+		expected string
+	}{
+		{SQLStandard, `INSERT INTO "people" ("name", "age", "email") VALUES ('C:\dir\', 30, 'it''s');` + "\n"},
+		{SQLMySQL, "INSERT INTO `people` (`name`, `age`, `email`) VALUES ('C:\\\\dir\\\\', 30, 'it\\'s');\n"},
+	}
+	for _, tt := range tests {
+		adapter, err := NewCSVAdapter[Person](WithSQLSyntax(tt.syntax))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		var output bytes.Buffer
+		if err := adapter.ToSQLInserts(&output, "people", slices.Values(people)); err != nil {
+			t.Fatalf("failed to write inserts: %v", err)
+		}
+		if output.String() != tt.expected {
+			t.Errorf("syntax %d: expected %q, got %q", tt.syntax, tt.expected, output.String())
+		}
+	}
+}
+
+func TestCopyReader(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, 30, fakemail}, {"Jane\tSmith\\", 25, ""}}
+	reader := adapter.CopyReader(slices.Values(people))
+	defer reader.Close()
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read copy data: %v", err)
+	}
+	expected := "John Doe\t30\t" + fakemail + "\n" +
+		"Jane\\tSmith\\\\\t25\t\\N\n"
+	if string(output) != expected {
+		t.Errorf("expected %q, got %q", expected, string(output))
+	}
+
+	reader = adapter.CopyReader(slices.Values([]Person{{"", 30, ""}}))
+	defer reader.Close()
+	if _, err := io.ReadAll(reader); ErrorCode(err) != CodeEmptyValue {
+		t.Errorf("expected %s, got %v", CodeEmptyValue, err)
+	}
+}
+
+type NullableHex struct {
+	Note  *string `csva:"note"`
+	Flags int     `csva:"flags,base=16"`
+}
+
+func TestSQLNulls(t *testing.T) {
+	adapter, err := NewCSVAdapter[NullableHex](WithDialect(PostgresDialect))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows := []NullableHex{{Note: nil, Flags: 16}}
+
+	var inserts bytes.Buffer
+	if err := adapter.ToSQLInserts(&inserts, "flags", slices.Values(rows)); err != nil {
+		t.Fatalf("failed to write inserts: %v", err)
+	}
+	expected := `INSERT INTO "flags" ("note", "flags") VALUES (NULL, '10');` + "\n"
+	if inserts.String() != expected {
+		t.Errorf("expected %q, got %q", expected, inserts.String())
+	}
+
+	var copyData bytes.Buffer
+	if err := adapter.ToCopy(&copyData, slices.Values(rows)); err != nil {
+		t.Fatalf("failed to write copy data: %v", err)
+	}
+	if expected := "\\N\t10\n"; copyData.String() != expected {
+		t.Errorf("expected %q, got %q", expected, copyData.String())
+	}
+}
@@ -0,0 +1,26 @@
+package csvadapter
+
+import "reflect"
+
+// isSQLNull reports whether t is one of the nullable types of database/sql,
+// e.g. sql.NullString or sql.Null[T], made of a value and a Valid flag
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t.PkgPath() == "database/sql" &&
+		t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" &&
+		t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// isNull reports whether v is a nil pointer or an invalid database/sql nullable value
+func isNull(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr {
+		return v.IsNil()
+	}
+	return isSQLNull(v.Type()) && !v.Field(1).Bool()
+}
+
+// isNullable reports whether t can hold nil values, i.e. is a pointer or a database/sql nullable type
+func isNullable(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr || isSQLNull(t)
+}
@@ -0,0 +1,165 @@
+package csvadapter
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLNullTypes(t *testing.T) {
+	type Account struct {
+		Name    sql.NullString  `csva:"name"`
+		Age     sql.NullInt64   `csva:"age"`
+		Balance sql.NullFloat64 `csva:"balance"`
+		Active  sql.NullBool    `csva:"active"`
+		Created sql.NullTime    `csva:"created"`
+		Level   sql.Null[uint8] `csva:"level"`
+		Email   sql.NullString  `csva:"email,required"`
+	}
+	adapter, err := NewCSVAdapter[Account](WithDialect(PostgresDialect))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "name,age,balance,active,created,level,email\n" +
+		"John Doe,30,1.5,true,2024-01-02T03:04:05Z,7," + fakemail + "\n" +
+		",\\N,,,,," + otherfakemail + "\n"
+	got, err := adapter.UnmarshalAll(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := []Account{
+		{
+			sql.NullString{String: name, Valid: true},
+			sql.NullInt64{Int64: age, Valid: true},
+			sql.NullFloat64{Float64: 1.5, Valid: true},
+			sql.NullBool{Bool: true, Valid: true},
+			sql.NullTime{Time: created, Valid: true},
+			sql.Null[uint8]{V: 7, Valid: true},
+			sql.NullString{String: fakemail, Valid: true},
+		},
+		{Email: sql.NullString{String: otherfakemail, Valid: true}},
+	}
+	if len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	written := "name,age,balance,active,created,level,email\n" +
+		"John Doe,30,1.500000,true,2024-01-02T03:04:05Z,7," + fakemail + "\n" +
+		"\\N,\\N,\\N,\\N,\\N,\\N," + otherfakemail + "\n"
+	if writer.String() != written {
+		t.Errorf("expected %q, got %q", written, writer.String())
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,balance,active,created,level,email\nJohn Doe,x,,,,,\n"))
+	if ErrorCode(err) != CodeParseInt {
+		t.Errorf("expected %s, got %v", CodeParseInt, err)
+	}
+	_, err = adapter.UnmarshalAll(strings.NewReader("name,age,balance,active,created,level,email\nJohn Doe,,,,,,\n"))
+	if !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected ErrEmptyValue for the required field, got %v", err)
+	}
+}
+
+// money is a database type implementing sql.Scanner and driver.Valuer only
+type money struct {
+	cents int64
+}
+
+func (m *money) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported source %T", src)
+	}
+	var units, hundredths int64
+	if _, err := fmt.Sscanf(s, "%d.%02d", &units, &hundredths); err != nil {
+		return err
+	}
+	m.cents = units*100 + hundredths
+	return nil
+}
+
+func (m money) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100), nil
+}
+
+func TestScannerValuer(t *testing.T) {
+	type Order struct {
+		ID    string `csva:"id"`
+		Total money  `csva:"total"`
+	}
+	adapter, err := NewCSVAdapter[Order]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	data := "id,total\nA1,12.05\n"
+	got, err := adapter.UnmarshalAll(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(got) != 1 || got[0].Total.cents != 1205 {
+		t.Errorf("expected 1205 cents, got %+v", got)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, got); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != data {
+		t.Errorf("expected %q, got %q", data, writer.String())
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("id,total\nA1,free\n")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestNullString(t *testing.T) {
+	type Row struct {
+		Name    string         `csva:"name"`
+		Age     *int           `csva:"age"`
+		Email   sql.NullString `csva:"email"`
+		Deleted *string        `csva:"deleted,null=NULL"`
+	}
+	adapter, err := NewCSVAdapter[Row](NullString(`\N`))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	rows, err := adapter.UnmarshalAll(strings.NewReader("name,age,email,deleted\n" +
+		`John Doe,\N,\N,NULL` + "\n" +
+		`Jane Smith,25,jane@example.com,\N` + "\n"))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if rows[0].Age != nil || rows[0].Email.Valid || rows[0].Deleted != nil {
+		t.Errorf("expected nil values, got %+v", rows[0])
+	}
+	if rows[1].Age == nil || *rows[1].Age != 25 || rows[1].Deleted == nil || *rows[1].Deleted != `\N` {
+		t.Errorf("expected values, got %+v", rows[1])
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, rows[:1]); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	expected := "name,age,email,deleted\nJohn Doe,\\N,\\N,NULL\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	if _, err := adapter.UnmarshalAll(strings.NewReader("name,age,email,deleted\n\\N,1,a,b\n")); !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("expected %v, got %v", ErrEmptyValue, err)
+	}
+}
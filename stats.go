@@ -0,0 +1,119 @@
+package csvadapter
+
+import (
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ColumnStats are the metrics of a column collected with the CollectStats option
+type ColumnStats struct {
+	Name     string  // name of the column in the header, column1, column2... without header
+	NonEmpty int     // cells that are not empty
+	Numbers  int     // non-empty cells that are numbers
+	Min, Max float64 // range of the cells that are numbers
+	Distinct int     // estimated number of distinct non-empty cells
+}
+
+// columnStats collects the metrics of a column
+type columnStats struct {
+	ColumnStats
+	distinct distinctSketch
+}
+
+// sketchPrecision is the number of bits of a hash selecting a register of a distinctSketch
+const sketchPrecision = 10
+
+// distinctSketch estimates the number of distinct values of a column (HyperLogLog),
+// within a few percents in constant memory
+type distinctSketch [1 << sketchPrecision]uint8
+
+func (s *distinctSketch) add(hash uint64) {
+	register := hash >> (64 - sketchPrecision)
+	// the lowest bit set bounds the rank of hashes with no other bit set
+	rank := uint8(bits.LeadingZeros64(hash<<sketchPrecision|1<<(sketchPrecision-1))) + 1
+	s[register] = max(s[register], rank)
+}
+
+func (s *distinctSketch) estimate() int {
+	m := float64(len(s))
+	sum, zeros := 0.0, 0
+	for _, rank := range s {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(estimate))
+}
+
+// hashCell hashes a cell for a distinctSketch (FNV-1a, then the finalizer of
+// MurmurHash3 spreading its bits), so the estimates do not vary between runs
+func hashCell(cell string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(cell); i++ {
+		hash ^= uint64(cell[i])
+		hash *= 1099511628211
+	}
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+// collectStats adds the cells of a record to the metrics of their columns
+func (d *Decoder[T]) collectStats(record []string) {
+	for len(d.columnStats) < len(record) {
+		d.columnStats = append(d.columnStats, columnStats{})
+	}
+	for i, cell := range record {
+		if cell == "" {
+			continue
+		}
+		stats := &d.columnStats[i]
+		stats.NonEmpty++
+		stats.distinct.add(hashCell(cell))
+		number, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			continue
+		}
+		if stats.Numbers == 0 || number < stats.Min {
+			stats.Min = number
+		}
+		if stats.Numbers == 0 || number > stats.Max {
+			stats.Max = number
+		}
+		stats.Numbers++
+	}
+}
+
+// ColumnStats returns the metrics of the columns collected so far with the
+// CollectStats option, in the order of the columns of the csv file
+//
+// like Stats, it must not be called while the rows are read in parallel.
+func (d *Decoder[T]) ColumnStats() []ColumnStats {
+	if !d.adapter.options.collectStats {
+		return nil
+	}
+	columns := make([]ColumnStats, max(len(d.header), len(d.columnStats)))
+	for i := range columns {
+		if i < len(d.columnStats) {
+			columns[i] = d.columnStats[i].ColumnStats
+			columns[i].Distinct = d.columnStats[i].distinct.estimate()
+		}
+		if i < len(d.header) {
+			columns[i].Name = d.header[i]
+		} else {
+			columns[i].Name = "column" + strconv.Itoa(i+1)
+		}
+	}
+	return columns
+}
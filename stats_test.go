@@ -0,0 +1,61 @@
+package csvadapter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestColumnStats(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		adapter, err := NewCSVAdapter[Person](Parallel(workers), CollectStats(true), SkipInvalidRows(true))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+
+		data := "name,age,email\n" +
+			"John Doe,30," + fakemail + "\n" +
+			"Jane Smith,invalid," + otherfakemail + "\n" +
+			"Kid,12,\n" +
+			"Jane Smith,25," + otherfakemail + ",extra\n"
+		decoder, err := adapter.NewDecoder(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to create decoder: %v", err)
+		}
+		for range decoder.All() {
+		}
+
+		expected := []ColumnStats{
+			{Name: "name", NonEmpty: 3, Distinct: 3},
+			{Name: "age", NonEmpty: 3, Numbers: 2, Min: 12, Max: 30, Distinct: 3},
+			{Name: "email", NonEmpty: 2, Distinct: 2},
+		}
+		if stats := decoder.ColumnStats(); fmt.Sprint(stats) != fmt.Sprint(expected) {
+			t.Errorf("workers %d: expected %+v, got %+v", workers, expected, stats)
+		}
+	}
+
+	// the estimate stays close for many distinct values
+	adapter, err := NewCSVAdapter[Person](CollectStats(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	var data strings.Builder
+	data.WriteString("name,age,email\n")
+	for i := range 10000 {
+		fmt.Fprintf(&data, "person%d,%d,%s\n", i, i%50, fakemail)
+	}
+	decoder, err := adapter.NewDecoder(strings.NewReader(data.String()))
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	for range decoder.All() {
+	}
+	stats := decoder.ColumnStats()
+	if stats[0].Distinct < 9000 || stats[0].Distinct > 11000 || stats[1].Distinct < 48 || stats[1].Distinct > 52 || stats[2].Distinct != 1 {
+		t.Errorf("unexpected distinct counts %+v", stats)
+	}
+	if stats[1].Min != 0 || stats[1].Max != 49 || stats[1].Numbers != 10000 {
+		t.Errorf("unexpected range %+v", stats[1])
+	}
+}
@@ -0,0 +1,159 @@
+package csvadapter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// utf8BOM is the byte order mark some tools (e.g. Excel) put at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Compression is the compression format of csv files
+type Compression int
+
+const (
+	CompressionNone Compression = iota // plain csv (default)
+	CompressionGzip                    // gzip compressed csv
+	CompressionAuto                    // detect gzip when reading, write plain csv
+)
+
+// gzipMagic are the first bytes of gzip streams
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// defaultBufferSize is the size of the buffers of bufio, used unless set with
+// the ReadBufferSize and WriteBufferSize options
+const defaultBufferSize = 4096
+
+// flushCloser flushes a buffered writer when closed
+type flushCloser struct {
+	*bufio.Writer
+}
+
+func (f flushCloser) Close() error {
+	return f.Flush()
+}
+
+// openReader prepares the input of FromCSV
+//
+// it returns the reader of the csv data and the number of bytes consumed before it (e.g. a BOM
+// or the lines skipped with the SkipRows option).
+// Offsets are counted on the decompressed and decoded data.
+func (c csvAdapterOptions) openReader(reader io.Reader) (io.Reader, int64, error) {
+	bufferSize := c.readBufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	compression := c.compression
+	if compression == CompressionAuto {
+		compression = CompressionNone
+		buffered := bufio.NewReaderSize(reader, bufferSize)
+		if prefix, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(prefix, gzipMagic) {
+			compression = CompressionGzip
+		}
+		reader = buffered
+	} else if compression == CompressionGzip && c.readBufferSize > 0 {
+		// gzip reads the compressed data through a default sized buffer otherwise
+		reader = bufio.NewReaderSize(reader, bufferSize)
+	}
+	if compression == CompressionGzip {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, 0, errors.Join(ErrReadingCSV, err)
+		}
+		reader = gzipReader
+	}
+	if c.decodeCharset != nil {
+		reader = c.decodeCharset(reader)
+	}
+	buffered := bufio.NewReaderSize(reader, bufferSize)
+	consumed := int64(0)
+
+	// strip a leading UTF-8 BOM
+	if prefix, _ := buffered.Peek(len(utf8BOM)); bytes.Equal(prefix, utf8BOM) {
+		n, err := buffered.Discard(len(utf8BOM))
+		consumed += int64(n)
+		if err != nil {
+			return nil, consumed, errors.Join(ErrReadingCSV, err)
+		}
+	}
+
+	// discard the preamble lines before the header
+	for range c.skipRows {
+		line, err := buffered.ReadSlice('\n')
+		consumed += int64(len(line))
+		for err == bufio.ErrBufferFull {
+			line, err = buffered.ReadSlice('\n')
+			consumed += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, consumed, errors.Join(ErrReadingCSV, err)
+		}
+	}
+
+	return buffered, consumed, nil
+}
+
+// checkBOM checks that the output written with a BOM is UTF-8
+//
+// passed through a charset encoder, the UTF-8 BOM would come out as other characters.
+func (c csvAdapterOptions) checkBOM() error {
+	if c.writeBOM && c.encodeCharset != nil {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("WriteBOM writes a UTF-8 byte order mark, it cannot be used with a charset"))
+	}
+	return nil
+}
+
+// openWriter prepares the output of ToCSV
+//
+// the returned close function must be called once the csv data is written;
+// it does not close the writer passed in and can be called more than once.
+func (c csvAdapterOptions) openWriter(writer io.Writer) (io.Writer, func() error, error) {
+	if err := c.checkBOM(); err != nil {
+		return nil, nil, err
+	}
+	// layers wrapping the writer, closed from the outermost
+	var closers []io.Closer
+	closed := false
+	closeAll := func() error {
+		if closed {
+			return nil
+		}
+		closed = true
+		var errs []error
+		for _, closer := range slices.Backward(closers) {
+			errs = append(errs, closer.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	if c.writeBufferSize > 0 && (c.compression == CompressionGzip || c.encodeCharset != nil) {
+		// buffer the output of the layers below the csv writer, flushed when closed
+		buffered := bufio.NewWriterSize(writer, c.writeBufferSize)
+		writer = buffered
+		closers = append(closers, flushCloser{buffered})
+	}
+	if c.compression == CompressionGzip {
+		gzipWriter := gzip.NewWriter(writer)
+		writer = gzipWriter
+		closers = append(closers, gzipWriter)
+	}
+	if c.encodeCharset != nil {
+		writer = c.encodeCharset(writer)
+		if closer, ok := writer.(io.Closer); ok {
+			closers = append(closers, closer)
+		}
+	}
+	if c.writeBOM {
+		if _, err := writer.Write(utf8BOM); err != nil {
+			return nil, nil, errors.Join(ErrReadingCSV, err, closeAll())
+		}
+	}
+	return writer, closeAll, nil
+}
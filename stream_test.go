@@ -0,0 +1,247 @@
+package csvadapter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestBOM(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](WriteBOM(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, age, fakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "\ufeffname,age,email\nJohn Doe,30," + fakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	rows, err := adapter.FromCSV(bytes.NewReader(writer.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for person, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != people[0] {
+			t.Errorf("expected %+v, got %+v", people[0], person)
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](Windows1252())
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{"Zoë €uro", age, fakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	expected := "name,age,email\nZo\xeb \x80uro,30," + fakemail + "\n"
+	if writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+
+	rows, err := adapter.FromCSV(bytes.NewReader(writer.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	for person, err := range rows {
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if person != people[0] {
+			t.Errorf("expected %+v, got %+v", people[0], person)
+		}
+	}
+
+	t.Run("unrepresentable", func(t *testing.T) {
+		adapter, err := NewCSVAdapter[Person](Latin1())
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		err = adapter.ToCSV(&bytes.Buffer{}, slices.Values([]Person{{"€", age, ""}}))
+		if !errors.Is(err, ErrCharset) {
+			t.Errorf("expected ErrCharset, got %v", err)
+		}
+	})
+
+	t.Run("bom", func(t *testing.T) {
+		if _, err := NewCSVAdapter[Person](Latin1(), WriteBOM(true)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig, got %v", err)
+		}
+		if err := adapter.ToCSV(&bytes.Buffer{}, slices.Values(people), WriteBOM(true)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig, got %v", err)
+		}
+	})
+}
+
+func TestCompression(t *testing.T) {
+	adapter, err := NewCSVAdapter[Person](WithCompression(CompressionGzip), WriteBOM(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	people := []Person{{name, age, fakemail}, {othername, otherage, otherfakemail}}
+	writer := &bytes.Buffer{}
+	if err := adapter.ToCSV(writer, slices.Values(people)); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(writer.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to open gzip: %v", err)
+	}
+	plain, err := io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+	expected := "\ufeffname,age,email\nJohn Doe,30," + fakemail + "\nJane Smith,25," + otherfakemail + "\n"
+	if string(plain) != expected {
+		t.Errorf("expected %q, got %q", expected, plain)
+	}
+
+	for _, compression := range []Compression{CompressionGzip, CompressionAuto} {
+		readAdapter, err := NewCSVAdapter[Person](WithCompression(compression))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		rows, err := readAdapter.FromCSV(bytes.NewReader(writer.Bytes()))
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		idx := 0
+		for person, err := range rows {
+			if err != nil {
+				t.Fatalf("failed to read person: %v", err)
+			}
+			if person != people[idx] {
+				t.Errorf("expected %+v, got %+v", people[idx], person)
+			}
+			idx++
+		}
+		if idx != len(people) {
+			t.Errorf("expected %d people, got %d", len(people), idx)
+		}
+	}
+
+	t.Run("auto with plain csv", func(t *testing.T) {
+		readAdapter, err := NewCSVAdapter[Person](WithCompression(CompressionAuto))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		rows, err := readAdapter.FromCSV(bytes.NewReader(plain))
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		for _, err := range rows {
+			if err != nil {
+				t.Fatalf("failed to read person: %v", err)
+			}
+		}
+	})
+
+	t.Run("invalid gzip", func(t *testing.T) {
+		readAdapter, err := NewCSVAdapter[Person](WithCompression(CompressionGzip))
+		if err != nil {
+			t.Fatalf("failed to create csva: %v", err)
+		}
+		if _, err := readAdapter.FromCSV(bytes.NewReader(plain)); !errors.Is(err, gzip.ErrHeader) {
+			t.Errorf("expected gzip.ErrHeader, got %v", err)
+		}
+	})
+}
+
+func TestSkipRows(t *testing.T) {
+	type Row struct {
+		Name   string `csva:"name"`
+		Age    int    `csva:"age"`
+		Offset int64  `csva:"-,byte_offset"`
+	}
+	adapter, err := NewCSVAdapter[Row](SkipRows(2))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	preamble := "Report \"people\", exported\nGenerated: today\n"
+	rows, err := adapter.UnmarshalAll(bytes.NewReader([]byte(preamble + "name,age\nJohn Doe,30\n")))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := Row{name, age, int64(len(preamble) + len("name,age\n"))}
+	if len(rows) != 1 || rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, rows)
+	}
+
+	if _, err := adapter.UnmarshalAll(bytes.NewReader([]byte("Report\n"))); !errors.Is(err, ErrReadingCSVLines) || !errors.Is(err, io.EOF) {
+		t.Errorf("expected %v, got %v", ErrReadingCSVLines, err)
+	}
+}
+
+// countingWriter counts the writes to the underlying writer
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+// largestReadReader records the largest buffer passed to Read
+type largestReadReader struct {
+	io.Reader
+	largest int
+}
+
+func (r *largestReadReader) Read(p []byte) (int, error) {
+	r.largest = max(r.largest, len(p))
+	return r.Reader.Read(p)
+}
+
+func TestBufferSizes(t *testing.T) {
+	people := slices.Repeat([]Person{{name, age, fakemail}}, 1000)
+	for _, compression := range []Compression{CompressionNone, CompressionGzip} {
+		writes := map[int]int{}
+		for _, size := range []int{0, 1 << 16} {
+			adapter, err := NewCSVAdapter[Person](WriteBufferSize(size), ReadBufferSize(size), WithCompression(compression))
+			if err != nil {
+				t.Fatalf("failed to create csva: %v", err)
+			}
+			writer := &countingWriter{}
+			if err := adapter.MarshalAll(writer, people); err != nil {
+				t.Fatalf("failed to write CSV: %v", err)
+			}
+			writes[size] = writer.writes
+
+			reader := &largestReadReader{Reader: bytes.NewReader(writer.Bytes())}
+			read, err := adapter.UnmarshalAll(reader)
+			if err != nil {
+				t.Fatalf("failed to read CSV: %v", err)
+			}
+			if !slices.Equal(read, people) {
+				t.Errorf("expected %d people, got %d", len(people), len(read))
+			}
+			if size > 0 && reader.largest != size {
+				t.Errorf("expected reads of %d bytes, got %d", size, reader.largest)
+			}
+		}
+		if writes[1<<16] >= writes[0] {
+			t.Errorf("compression %d: expected fewer writes with a larger buffer, got %d and %d", compression, writes[1<<16], writes[0])
+		}
+	}
+}
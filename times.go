@@ -0,0 +1,129 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// formats of time fields written as integer epoch timestamps
+const (
+	_TIME_UNIX      = "unix"
+	_TIME_UNIXMILLI = "unixmilli"
+	_TIME_UNIXMICRO = "unixmicro"
+	_TIME_UNIXNANO  = "unixnano"
+)
+
+// namedLayouts are the layouts of the time package that the format tag option accepts by name
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// isTimeField reports whether t is a time.Time, possibly behind pointers,
+// slices (for the sep tag option) or sql.NullTime
+func isTimeField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if isSQLNull(t) {
+		t = t.Field(0).Type
+	}
+	return t == timeType
+}
+
+// parseTime parses a cell of a time field with the layouts of the format tag option,
+// tried in order
+//
+// timestamps without a zone are in the location of the field, UTC if not set.
+func (f *field) parseTime(value string) (time.Time, error) {
+	if len(f.layouts) == 0 {
+		return f.parseLayout(time.RFC3339Nano, value)
+	}
+	var err error
+	for _, layout := range f.layouts {
+		var t time.Time
+		if t, err = f.parseLayout(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if len(f.layouts) == 1 {
+		return time.Time{}, err
+	}
+	return time.Time{}, errors.Join(fmt.Errorf("time %q matches none of the layouts %q", value, f.layouts), err)
+}
+
+// parseLayout parses a cell of a time field with a layout
+func (f *field) parseLayout(layout, value string) (time.Time, error) {
+	switch layout {
+	case _TIME_UNIX, _TIME_UNIXMILLI, _TIME_UNIXMICRO, _TIME_UNIXNANO:
+		return f.parseEpoch(layout, value)
+	}
+	if f.location == nil {
+		return time.Parse(layout, value)
+	}
+	return time.ParseInLocation(layout, value, f.location)
+}
+
+// formatTime formats a time field with the first layout of the format tag option,
+// in UTC with the WriteUTC option or in the location of the field if set
+func (f *field) formatTime(t time.Time) string {
+	layout := time.RFC3339Nano
+	if len(f.layouts) > 0 {
+		layout = f.layouts[0]
+	}
+	if f.utc {
+		t = t.UTC()
+	} else if f.location != nil {
+		t = t.In(f.location)
+	}
+	switch layout {
+	case _TIME_UNIX:
+		return strconv.FormatInt(t.Unix(), 10)
+	case _TIME_UNIXMILLI:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case _TIME_UNIXMICRO:
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case _TIME_UNIXNANO:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	return t.Format(layout)
+}
+
+// parseEpoch parses an integer epoch timestamp in the unit of an epoch format
+func (f *field) parseEpoch(format, value string) (time.Time, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	switch format {
+	case _TIME_UNIX:
+		t = time.Unix(i, 0)
+	case _TIME_UNIXMILLI:
+		t = time.UnixMilli(i)
+	case _TIME_UNIXMICRO:
+		t = time.UnixMicro(i)
+	default:
+		t = time.Unix(0, i)
+	}
+	if f.location != nil {
+		return t.In(f.location), nil
+	}
+	return t.UTC(), nil
+}
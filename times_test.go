@@ -0,0 +1,168 @@
+package csvadapter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	type Event struct {
+		Local time.Time `csva:"local,format=DateTime,tz=Europe/Paris"`
+		Naive time.Time `csva:"naive,format=2006-01-02 15:04"`
+		Zoned time.Time `csva:"zoned"`
+	}
+	adapter, err := NewCSVAdapter[Event]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := "local,naive,zoned\n2024-07-01 12:00:00,2024-07-01 12:00,2024-07-01T12:00:00+02:00\n"
+	events, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if expected := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC); !events[0].Local.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, events[0].Local)
+	}
+	if expected := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC); !events[0].Naive.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, events[0].Naive)
+	}
+	if expected := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC); !events[0].Zoned.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, events[0].Zoned)
+	}
+
+	// written back in the location of the field
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, events); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %q, got %q", csvData, writer.String())
+	}
+
+	// the Location option applies to the fields without tz, WriteUTC to all
+	adapter, err = NewCSVAdapter[Event](Location(paris), WriteUTC(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	events, err = adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if expected := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC); !events[0].Naive.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, events[0].Naive)
+	}
+	writer.Reset()
+	if err := adapter.MarshalAll(writer, events); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if expected := "local,naive,zoned\n2024-07-01 10:00:00,2024-07-01 10:00,2024-07-01T10:00:00Z\n"; writer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, writer.String())
+	}
+}
+
+func TestTimeTagErrors(t *testing.T) {
+	type NotTime struct {
+		Name string `csva:"name,format=DateOnly"`
+	}
+	type UnknownZone struct {
+		At time.Time `csva:"at,tz=Mars/Olympus"`
+	}
+	for _, err := range []error{second(NewCSVAdapter[NotTime]()), second(NewCSVAdapter[UnknownZone]())} {
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag, got %v", err)
+		}
+	}
+}
+
+func TestTimeEpoch(t *testing.T) {
+	type LogLine struct {
+		Seconds time.Time  `csva:"ts,format=unix"`
+		Millis  time.Time  `csva:"ts_ms,format=unixmilli"`
+		Micros  *time.Time `csva:"ts_us,format=unixmicro,omitempty"`
+		Nanos   time.Time  `csva:"ts_ns,format=unixnano"`
+	}
+	adapter, err := NewCSVAdapter[LogLine]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := "ts,ts_ms,ts_us,ts_ns\n1700000000,1700000000123,1700000000123456,1700000000123456789\n"
+	lines, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	line := lines[0]
+	if expected := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC); line.Seconds != expected {
+		t.Errorf("expected %v, got %v", expected, line.Seconds)
+	}
+	if expected := time.Date(2023, 11, 14, 22, 13, 20, 123000000, time.UTC); line.Millis != expected {
+		t.Errorf("expected %v, got %v", expected, line.Millis)
+	}
+	if expected := time.Date(2023, 11, 14, 22, 13, 20, 123456000, time.UTC); line.Micros == nil || *line.Micros != expected {
+		t.Errorf("expected %v, got %v", expected, line.Micros)
+	}
+	if expected := time.Date(2023, 11, 14, 22, 13, 20, 123456789, time.UTC); line.Nanos != expected {
+		t.Errorf("expected %v, got %v", expected, line.Nanos)
+	}
+
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, lines); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != csvData {
+		t.Errorf("expected %q, got %q", csvData, writer.String())
+	}
+
+	_, err = adapter.UnmarshalAll(strings.NewReader("ts,ts_ms,ts_us,ts_ns\n2023-11-14,0,0,0\n"))
+	if !errors.Is(err, ErrParsingType) {
+		t.Errorf("expected ErrParsingType, got %v", err)
+	}
+}
+
+func TestTimeLayouts(t *testing.T) {
+	type Order struct {
+		Date time.Time `csva:"date,format=DateOnly|01/02/2006|RFC3339|unix"`
+	}
+	adapter, err := NewCSVAdapter[Order]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := "date\n2024-03-15\n03/15/2024\n2024-03-15T00:00:00Z\n1710460800\n"
+	orders, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	expected := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	for i, order := range orders {
+		if !order.Date.Equal(expected) {
+			t.Errorf("row %d: expected %v, got %v", i, expected, order.Date)
+		}
+	}
+
+	// the first layout is written
+	writer := &bytes.Buffer{}
+	if err := adapter.MarshalAll(writer, orders[:1]); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	if writer.String() != "date\n2024-03-15\n" {
+		t.Errorf("expected %q, got %q", "date\n2024-03-15\n", writer.String())
+	}
+
+	// the error lists the layouts tried
+	_, err = adapter.UnmarshalAll(strings.NewReader("date\n15.03.2024\n"))
+	if !errors.Is(err, ErrParsingType) {
+		t.Fatalf("expected ErrParsingType, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"2006-01-02" "01/02/2006"`) {
+		t.Errorf("expected the layouts in the error, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package csvadapter
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// Union reads csv files mixing several kinds of rows, e.g. the records of a
+// ledger export, the cell of a discriminator column selecting the struct of each row
+//
+// the structs are registered with AddVariant and implement the interface I.
+type Union[I any] struct {
+	column   string // name of the discriminator column
+	options  *csvAdapterOptions
+	variants map[string]func(header []string, source any) (*variantDecoder[I], error)
+}
+
+// variantDecoder decodes the rows of a kind
+type variantDecoder[I any] struct {
+	decode  func(r rawRecord) (I, bool, error) // decodes a row, reports whether it is yielded
+	stopped func() bool
+}
+
+// headerReader is a RecordReader reading only a header
+type headerReader []string
+
+func (h *headerReader) Read() ([]string, error) {
+	if *h == nil {
+		return nil, io.EOF
+	}
+	record := *h
+	*h = nil
+	return record, nil
+}
+
+// NewUnion creates a Union selecting the struct of each row by the cell of column
+//
+// I must be an interface. options describe the file read by FromCSV (e.g. Comma,
+// SkipRows or WithCompression); the rows of each variant are handled with the
+// options of its adapter.
+func NewUnion[I any](column string, options ...csvAdapterOption) (*Union[I], error) {
+	if reflect.TypeFor[I]().Kind() != reflect.Interface {
+		return nil, errors.Join(ErrInvalidConfig, fmt.Errorf("%s is not an interface", reflect.TypeFor[I]()))
+	}
+	o := newCSVAdapterOptions()
+	for _, option := range options {
+		option(o)
+	}
+	return &Union[I]{
+		column:   column,
+		options:  o,
+		variants: make(map[string]func(header []string, source any) (*variantDecoder[I], error)),
+	}, nil
+}
+
+// AddVariant registers the rows whose discriminator cell is value, decoded by adapter
+//
+// the rows of the variant are mapped from the header of the file and decoded
+// like by FromCSV: the options of adapter applying to single rows (e.g. Filter,
+// MapRead, OnRowError, MaxErrors, DedupeBy) apply to the rows of its kind.
+// The header has the columns of every kind, so adapters with the StrictHeader
+// option are rejected with ErrInvalidConfig.
+func AddVariant[T, I any](u *Union[I], value string, adapter *CSVAdapter[T]) error {
+	var TEmpty T
+	if _, ok := any(TEmpty).(I); !ok {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("%s does not implement %s", reflect.TypeFor[T](), reflect.TypeFor[I]()))
+	}
+	if adapter.options.strictHeader {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("variant %q: StrictHeader rejects the columns of the other variants", value))
+	}
+	if _, ok := u.variants[value]; ok {
+		return errors.Join(ErrInvalidConfig, fmt.Errorf("variant %q already registered", value))
+	}
+	u.variants[value] = func(header []string, source any) (*variantDecoder[I], error) {
+		reader := headerReader(slices.Clone(header))
+		// the union reads the source, the decoder must not close it
+		d, err := adapter.newDecoder(&reader, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		if named, ok := source.(interface{ Name() string }); ok {
+			d.sourceName = named.Name()
+		}
+		return &variantDecoder[I]{
+			decode: func(r rawRecord) (I, bool, error) {
+				item, err := d.decodeRow(r)
+				item, ok, err := d.settle(item, err, r.line, r.record)
+				if !ok || err != nil {
+					var IEmpty I
+					return IEmpty, ok, err
+				}
+				return any(item).(I), true, nil
+			},
+			stopped: func() bool { return d.stopped },
+		}, nil
+	}
+	return nil
+}
+
+// FromCSV reads the rows of a csv file with a header, each decoded by the
+// variant of its discriminator cell
+//
+// a file without the discriminator column fails here, before any row is read.
+// Rows with an unregistered discriminator fail with ErrUnknownVariant; the
+// header errors of a variant (e.g. a missing column) are reported by its first row
+// and stop the iteration.
+func (u *Union[I]) FromCSV(reader io.Reader) (iter.Seq2[I, error], error) {
+	input, consumed, err := u.options.openReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	csvReader := csv.NewReader(input)
+	u.options.applyReader(csvReader)
+	csvReader.FieldsPerRecord = -1 // the kinds of rows can have their own width
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.Join(ErrReadingCSVLines, err)
+	}
+	header = slices.Clone(header)
+	column := slices.IndexFunc(header, func(name string) bool {
+		return u.options.headerKey(name) == u.options.headerKey(u.column)
+	})
+	if column < 0 {
+		return nil, errors.Join(ErrFieldNotFound, fmt.Errorf("discriminator column %q not found", u.column))
+	}
+
+	return func(yield func(I, error) bool) {
+		var IEmpty I
+		decoders := make(map[string]*variantDecoder[I])
+		for line := 1; ; line++ {
+			offset := consumed + csvReader.InputOffset()
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			r := rawRecord{record: record, offset: offset, end: consumed + csvReader.InputOffset(), line: line}
+			if err != nil {
+				var parseErr *csv.ParseError
+				err = errors.Join(ErrReadingCSVLines, ReadingError{Line: line, Code: CodeMalformedRow, Err: err, Record: slices.Clone(record)}, err)
+				if !yield(IEmpty, err) || !errors.As(err, &parseErr) {
+					return
+				}
+				continue
+			}
+			value := ""
+			if column < len(record) {
+				value = record[column]
+			}
+			d, ok := decoders[value]
+			if !ok {
+				variant, ok := u.variants[value]
+				if !ok {
+					err := errors.Join(ErrProcessingCSVLines, ReadingError{
+						Line:   line,
+						Code:   CodeUnknownVariant,
+						Column: column + 1,
+						Value:  value,
+						Err:    ErrUnknownVariant,
+						Record: slices.Clone(record),
+					}, ErrUnknownVariant)
+					if !yield(IEmpty, err) {
+						return
+					}
+					continue
+				}
+				if d, err = variant(header, reader); err != nil {
+					yield(IEmpty, err)
+					return
+				}
+				decoders[value] = d
+			}
+			if item, ok, err := d.decode(r); ok && !yield(item, err) {
+				return
+			}
+			if d.stopped() {
+				return
+			}
+		}
+	}, nil
+}
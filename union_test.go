@@ -0,0 +1,106 @@
+package csvadapter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type LedgerEntry interface {
+	Amount() float64
+}
+
+type Payment struct {
+	Payee string  `csva:"party"`
+	Sum   float64 `csva:"amount"`
+}
+
+func (p Payment) Amount() float64 { return -p.Sum }
+
+type Deposit struct {
+	Payer string  `csva:"party"`
+	Sum   float64 `csva:"amount"`
+	Note  string  `csva:"note,omitempty"`
+}
+
+func (d *Deposit) Amount() float64 { return d.Sum }
+
+func TestUnion(t *testing.T) {
+	union, err := NewUnion[LedgerEntry]("record_type")
+	if err != nil {
+		t.Fatalf("failed to create union: %v", err)
+	}
+	payments, err := NewCSVAdapter[Payment]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	deposits, err := NewCSVAdapter[*Deposit]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if err := AddVariant(union, "PAY", payments); err != nil {
+		t.Fatalf("failed to add variant: %v", err)
+	}
+	if err := AddVariant(union, "DEP", deposits); err != nil {
+		t.Fatalf("failed to add variant: %v", err)
+	}
+	if err := AddVariant(union, "DEP", deposits); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v for a duplicate variant, got %v", ErrInvalidConfig, err)
+	}
+	deposit, err := NewCSVAdapter[Deposit]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if err := AddVariant(union, "DEP2", deposit); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v for a type not implementing the interface, got %v", ErrInvalidConfig, err)
+	}
+	strict, err := NewCSVAdapter[Payment](StrictHeader(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	if err := AddVariant(union, "PAY2", strict); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v for a strict header, got %v", ErrInvalidConfig, err)
+	}
+	if _, err := NewUnion[Payment]("record_type"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected %v for a struct, got %v", ErrInvalidConfig, err)
+	}
+
+	data := "record_type,party,amount,note\n" +
+		"PAY,Landlord,800,\n" +
+		"DEP,Employer,2500,salary\n" +
+		"FEE,Bank,2,\n" +
+		"PAY,Grocer,abc,\n" +
+		"DEP,Friend,20,\n"
+	rows, err := union.FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	var entries []LedgerEntry
+	var errs []error
+	for entry, err := range rows {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 || len(errs) != 2 {
+		t.Fatalf("expected 3 entries and 2 errors, got %v and %v", entries, errs)
+	}
+	if payment, ok := entries[0].(Payment); !ok || payment.Payee != "Landlord" || payment.Amount() != -800 {
+		t.Errorf("unexpected first entry %#v", entries[0])
+	}
+	if deposit, ok := entries[1].(*Deposit); !ok || deposit.Note != "salary" || deposit.Amount() != 2500 {
+		t.Errorf("unexpected second entry %#v", entries[1])
+	}
+	if readingErr, ok := AsReadingError(errs[0]); !ok || ErrorCode(errs[0]) != CodeUnknownVariant || readingErr.Line != 3 || readingErr.Value != "FEE" {
+		t.Errorf("expected %s at line 3, got %v", CodeUnknownVariant, errs[0])
+	}
+	if readingErr, ok := AsReadingError(errs[1]); !ok || ErrorCode(errs[1]) != CodeParseFloat || readingErr.Line != 4 {
+		t.Errorf("expected %s at line 4, got %v", CodeParseFloat, errs[1])
+	}
+
+	if _, err := union.FromCSV(strings.NewReader("party,amount\n")); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected %v without discriminator column, got %v", ErrFieldNotFound, err)
+	}
+}
@@ -0,0 +1,64 @@
+package csvadapter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// uniqueSet tracks the values of a column with the unique tag option
+type uniqueSet struct {
+	field int            // index of the field
+	lines map[string]int // line of the first row of each value
+}
+
+// sets the maximum number of values tracked per unique column
+//
+// once n values of a column with the unique tag option are tracked, the next
+// new values are not tracked anymore, bounding the memory used for large
+// files: duplicates of the tracked values are still detected. 0 (the default)
+// means no limit.
+func UniqueLimit(n int) csvAdapterOption {
+	return func(o *csvAdapterOptions) {
+		o.uniqueLimit = n
+	}
+}
+
+// newUniqueSets returns the sets of the fields with the unique tag option
+func (c *CSVAdapter[T]) newUniqueSets() []uniqueSet {
+	var sets []uniqueSet
+	for i, f := range c.fields {
+		if f.unique {
+			sets = append(sets, uniqueSet{field: i, lines: make(map[string]int)})
+		}
+	}
+	return sets
+}
+
+// checkUnique checks that the cells of the unique columns of a row were not seen before
+func (d *Decoder[T]) checkUnique(line int, record []string) error {
+	limit := d.adapter.options.uniqueLimit
+	for _, set := range d.uniques {
+		f := &d.adapter.fields[set.field]
+		column := d.columns[set.field]
+		if column < 0 || column >= len(record) {
+			continue
+		}
+		// like in SQL, empty and null cells are not values
+		value := d.adapter.normalizeCell(f, record[column])
+		if value == "" {
+			continue
+		}
+		if first, seen := set.lines[value]; seen {
+			return newFieldError(
+				ReadingError{Line: line, Code: CodeDuplicateValue, Column: column + 1, Value: value, Record: slices.Clone(record)},
+				*f,
+				errors.Join(ErrDuplicateValue, fmt.Errorf("value %q already at line %d", value, first)),
+			)
+		}
+		if limit <= 0 || len(set.lines) < limit {
+			set.lines[value] = line
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package csvadapter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnique(t *testing.T) {
+	type Account struct {
+		ID    string `csva:"id,unique"`
+		Email string `csva:"email,unique,omitempty"`
+	}
+	adapter, err := NewCSVAdapter[Account](SkipInvalidRows(true))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	csvData := "id,email\n" +
+		"1," + fakemail + "\n" +
+		"2,\n" +
+		"3,\n" + // empty cells are not values
+		"1," + otherfakemail + "\n" +
+		"4," + fakemail + "\n"
+	accounts, err := adapter.UnmarshalAll(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(accounts) != 3 {
+		t.Errorf("expected 3 accounts, got %+v", accounts)
+	}
+
+	adapter, err = NewCSVAdapter[Account]()
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+	rows, err := adapter.FromCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	var errs []error
+	for _, err := range rows {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	readingErr, ok := AsReadingError(errs[1])
+	if !ok || !errors.Is(errs[1], ErrDuplicateValue) || readingErr.Line != 5 || readingErr.FieldAlias != "email" || readingErr.Column != 2 {
+		t.Errorf("unexpected error %v", errs[1])
+	}
+	if !strings.Contains(errs[1].Error(), "already at line 1") {
+		t.Errorf("expected the line of the first value, got %v", errs[1])
+	}
+}
+
+func TestUniqueLimit(t *testing.T) {
+	type Account struct {
+		ID string `csva:"id,unique"`
+	}
+	adapter, err := NewCSVAdapter[Account](UniqueLimit(1))
+	if err != nil {
+		t.Fatalf("failed to create csva: %v", err)
+	}
+
+	// only the first value is tracked
+	_, err = adapter.UnmarshalAll(strings.NewReader("id\n1\n2\n2\n"))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	_, err = adapter.UnmarshalAll(strings.NewReader("id\n1\n2\n1\n"))
+	if ErrorCode(err) != CodeDuplicateValue {
+		t.Errorf("expected %s, got %v", CodeDuplicateValue, err)
+	}
+}